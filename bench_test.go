@@ -12,7 +12,7 @@ func makeState() *State {
 	rand.Seed(0)
 
 	state := NewState(FooLevel)
-	var input [res.Man_count]res.Packet
+	input := make([]res.Packet, len(state.Mans))
 
 	input[res.Man_Whip].X = proto.Int64(-80)
 	input[res.Man_Whip].Y = proto.Int64(-100)
@@ -32,7 +32,7 @@ func makeState() *State {
 	input[res.Man_Normal].KeyUp = Button_pressed
 
 	for i := 0; i < 5*TicksPerSecond; i++ {
-		state.Update(&input)
+		state.Update(input)
 	}
 
 	input[res.Man_Density].KeyUp = Button_pressed
@@ -41,7 +41,7 @@ func makeState() *State {
 	input[res.Man_Normal].KeyDown = Button_pressed
 
 	for i := 0; i < 5*TicksPerSecond; i++ {
-		state.Update(&input)
+		state.Update(input)
 	}
 
 	return state
@@ -56,95 +56,137 @@ func init() {
 func BenchmarkRender480p(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 720, 480))
 	*flagSplitScreen = false
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
 	}
 }
 
 func BenchmarkRender480pSS(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 720, 480))
 	*flagSplitScreen = true
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
 	}
 }
 
 func BenchmarkRender720p(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 1280, 720))
 	*flagSplitScreen = false
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
 	}
 }
 
 func BenchmarkRender720pSS(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 1280, 720))
 	*flagSplitScreen = true
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
 	}
 }
 
 func BenchmarkRender1080p(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 1920, 1080))
 	*flagSplitScreen = false
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
 	}
 }
 
 func BenchmarkRender1080pSS(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 1920, 1080))
 	*flagSplitScreen = true
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
 	}
 }
 
 func BenchmarkRender4K(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 4096, 2160))
 	*flagSplitScreen = false
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
 	}
 }
 
 func BenchmarkRender4KSS(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 4096, 2160))
 	*flagSplitScreen = true
-	Render(img, res.Man_Whip, benchState, nil)
+	Render(img, int(res.Man_Whip), benchState, nil)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		Render(img, res.Man_Whip, benchState, nil)
+		Render(img, int(res.Man_Whip), benchState, nil)
+	}
+}
+
+func makeCrowdedState(units int) *State {
+	rand.Seed(0)
+
+	state := NewState(FooLevel)
+	spawn := state.SpawnPoint
+
+	for i := 0; i < units; i++ {
+		// spread the units over the width of the level
+		state.SpawnPoint.X = (FooLevel.Min.X + rand.Int63n(FooLevel.Max.X-FooLevel.Min.X+1)) * TileSize * PixelSize
+
+		u := &Unit{}
+		if i%2 == 0 {
+			u.UnitData = &Lemon{ID: state.NextUnit}
+		} else {
+			u.UnitData = &Grub{}
+		}
+		u.Health = u.MaxHealth(state, u)
+		state.FindSpawnPosition(u)
+		state.Units[state.NextUnit] = u
+		state.NextUnit++
+	}
+
+	state.SpawnPoint = spawn
+
+	return state
+}
+
+// BenchmarkUpdate500Units simulates one second of game time per op. The server
+// keeps up as long as an op takes less than a second.
+func BenchmarkUpdate500Units(b *testing.B) {
+	state := makeCrowdedState(500)
+	input := make([]res.Packet, len(state.Mans))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < TicksPerSecond; j++ {
+			state.Update(input)
+		}
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/Rnoadm/wdvn/res"
 	"github.com/skelterjohn/go.wde"
 	"image"
+	"time"
 )
 
 func Client(addr string) {
@@ -34,7 +35,7 @@ func Client(addr string) {
 	defer Disconnect(read, write, errors)
 
 	var (
-		me        res.Man
+		me        int
 		state     *State
 		lastState []byte
 		lastTick  uint64
@@ -47,11 +48,23 @@ func Client(addr string) {
 
 	var (
 		renderResize = make(chan struct{}, 1)
-		renderMan    = make(chan res.Man, 1)
+		renderMan    = make(chan int, 1)
 		renderState  = make(chan *State, 1)
 		renderError  = make(chan error, 1)
+		renderScores = make(chan bool, 1)
 	)
-	go RenderThread(w, renderResize, renderMan, renderState, renderError)
+	go RenderThread(w, renderResize, renderMan, renderState, renderError, renderScores)
+
+	showScores := func(show bool) {
+		for {
+			select {
+			case renderScores <- show:
+			case <-renderScores:
+				continue
+			}
+			break
+		}
+	}
 
 	for {
 		select {
@@ -88,7 +101,7 @@ func Client(addr string) {
 				go Send(write, p)
 
 			case res.Type_SelectMan:
-				me = p.GetMan()
+				me = int(p.GetSlot())
 				for {
 					select {
 					case renderMan <- me:
@@ -150,8 +163,18 @@ func Client(addr string) {
 					break
 				}
 
+			case res.Type_Tuning:
+				err := ApplyTuning(p.GetData())
+				if err != nil {
+					panic(err)
+				}
+
 			case res.Type_World:
 				world = LoadWorld(bytes.NewReader(p.GetData()))
+				world.loaded = time.Now()
+				if world.Meta.Title != "" {
+					w.SetTitle(world.Meta.Title)
+				}
 				if !noState {
 					state.world = world
 					for {
@@ -216,6 +239,14 @@ func Client(addr string) {
 						Type: Type_SelectMan,
 						Man:  Man_Normal,
 					})
+				case wde.KeyF5:
+					go Send(write, &res.Packet{
+						Type: Type_SelectMan,
+						Man:  Man_Portal,
+					})
+
+				case wde.KeyTab:
+					showScores(true)
 				}
 			case wde.KeyTypedEvent:
 				// TODO
@@ -240,6 +271,9 @@ func Client(addr string) {
 					input <- &res.Packet{
 						KeyRight: Button_released,
 					}
+
+				case wde.KeyTab:
+					showScores(false)
 				}
 			case wde.MouseDownEvent:
 				input <- Mouse(w, state, me, e.Where)
@@ -285,24 +319,29 @@ func Client(addr string) {
 	}
 }
 
-func RenderThread(w wde.Window, repaint <-chan struct{}, man <-chan res.Man, state <-chan *State, err <-chan error) {
+func RenderThread(w wde.Window, repaint <-chan struct{}, man <-chan int, state <-chan *State, err <-chan error, scores <-chan bool) {
 	defer quitWait.Done()
 
 	img := image.NewRGBA(w.Screen().Bounds())
-	var m res.Man
+	var m int
 	var s *State
 	var e error
+	var showScores bool
 	for {
 		if img.Rect != w.Screen().Bounds() {
 			img = image.NewRGBA(w.Screen().Bounds())
 		}
 		Render(img, m, s, e)
+		if showScores && s != nil && s.world != nil {
+			renderStats(img, s)
+		}
 		w.Screen().CopyRGBA(img, img.Rect)
 		w.FlushImage(img.Rect)
 		select {
 		case m = <-man:
 		case s = <-state:
 		case e = <-err:
+		case showScores = <-scores:
 		case <-repaint:
 		case <-quitRequest:
 			return
@@ -342,33 +381,29 @@ func Input(write chan<- *res.Packet, input <-chan *res.Packet) {
 	}
 }
 
-func Mouse(w wde.Window, state *State, me res.Man, mouse image.Point) *res.Packet {
+func Mouse(w wde.Window, state *State, me int, mouse image.Point) *res.Packet {
 	width, height := w.Size()
-	if *flagSplitScreen {
-		width /= 2
-		height /= 2
-
-		var them res.Man
-		if mouse.Y >= height {
-			them |= 1
-		}
-		if mouse.X >= width {
-			them |= 2
+	view := image.Rect(0, 0, width, height)
+	if *flagSplitScreen && state != nil && me < len(state.Mans) {
+		n := len(state.Mans)
+
+		them := me
+		for i := 0; i < n; i++ {
+			if mouse.In(splitScreen(view, n, i)) {
+				them = i
+				break
+			}
 		}
 
-		if me&1 == 1 {
-			mouse.Y -= height
-		}
-		if me&2 == 2 {
-			mouse.X -= width
-		}
+		view = splitScreen(view, n, them)
 
 		delta := state.Mans[them].Position.Sub(state.Mans[me].Position)
 		mouse.X += int(delta.X / PixelSize)
 		mouse.Y += int(delta.Y / PixelSize)
 	}
-	mouse.X -= width / 2
-	mouse.Y -= height / 2
+	mouse = mouse.Sub(view.Min)
+	mouse.X -= view.Dx() / 2
+	mouse.Y -= view.Dy() / 2
 	return &res.Packet{
 		X: proto.Int64(int64(mouse.X)),
 		Y: proto.Int64(int64(mouse.Y)),
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// comboFloater announces a combo that by did with, or to, the unit at.
+func (state *State) comboFloater(by, at *Unit, text string) {
+	state.Floaters = append(state.Floaters, Floater{
+		S:  text,
+		Fg: color.RGBA{255, 255, 255, 255},
+		Bg: by.Color(state, by),
+		X:  at.Position.X,
+		Y:  at.Position.Y - at.Size(state, at).Y,
+		T:  state.Tick,
+	})
+}
+
+// DensityBoost returns the extra jump speed u gets from a heavy DensityMan
+// standing on its head.
+func (state *State) DensityBoost(u *Unit) int64 {
+	var d *DensityMan
+	tr := state.Trace(u.Position, u.Position.Sub(Coord{0, 1}), u.Size(state, u), false)
+	o := tr.CollideFunc(func(o *Unit) bool {
+		var ok bool
+		d, ok = o.UnitData.(*DensityMan)
+		return ok && o != u && o.Health > 0
+	})
+	if o == nil || tr.Side != SideBottom || d.Gravity_ <= 0 {
+		return 0
+	}
+
+	// the DensityMan is thrown up fast enough to stay clear of the teammate
+	// despite falling faster, instead of weighing the jump down.
+	speed := ManData[u.UnitData.(Man).Man()].JumpSpeed
+	boost := speed * d.Gravity_ / Tuning.DensityMax
+	o.Velocity.Y = -(speed + boost) * (2*Gravity + d.Gravity_) / (2 * Gravity)
+	state.comboFloater(o, u, "DENSITY BOOST!")
+	return boost
+}
+
+// CannonHit is called when u runs into o. A DensityMan fired by a VacuumMan
+// hits harder the denser it is.
+func (state *State) CannonHit(u, o *Unit) {
+	d, ok := u.UnitData.(*DensityMan)
+	if !ok || d.LaunchedBy_.Zero() {
+		return
+	}
+	by := state.Deref(d.LaunchedBy_)
+	d.LaunchedBy_ = UnitRef{}
+	if state.Tick-d.Launched_ > Tuning.CannonTime || by == nil {
+		return
+	}
+
+	v := u.Velocity.Sub(o.Velocity)
+	speed := int64(math.Sqrt(float64(v.LengthSquared())))
+	o.Hurt(state, by, speed*u.Mass(state, u)/DamageFactor*(Gravity+d.Gravity_)/Gravity, DamagePound)
+	state.comboFloater(by, o, "DENSITY CANNON!")
+}
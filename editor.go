@@ -1,17 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"code.google.com/p/draw2d/draw2d"
-	"encoding/gob"
+	"code.google.com/p/goprotobuf/proto"
 	"fmt"
+	"github.com/Rnoadm/wdvn/res"
 	"github.com/skelterjohn/go.wde"
 	"image"
 	"image/color"
 	"image/draw"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
-func Editor(filename string) {
+// Editor edits the level in filename. If addr is set, it joins the edit
+// session there instead of loading the level, and filename is only where it
+// is saved, along with wherever the edit session saves it.
+func Editor(filename, addr string) {
 	graphicsInit()
 
 	defer quitWait.Done()
@@ -28,13 +35,63 @@ func Editor(filename string) {
 	var (
 		world      World
 		offX, offY int64
+		mouse      image.Point
+		selected   = -1 // entity being edited
+		trigger    = -1 // trigger being edited
+		corner     *Coord
+		ctrl       bool // a control key is held down
+		shift      bool // a shift key is held down
+		history    editHistory
 	)
 
+	var (
+		loadErr error     // why filename could not be loaded, if it exists
+		saved   [2]uint64 // version last saved
+		status  string    // what last happened to the file, shown under the brush
+		closing bool      // the window was closed with unsaved changes...
+		closeAt [2]uint64 // ...and nothing has been edited since
+		naming  bool      // a filename is being typed in to save as
+		name    string
+		title   string
+	)
+
+	var (
+		tool     EditorTool = Tool_Edit
+		lastTool EditorTool = Tool_Paint // tool to go back to after picking
+		brush               = WorldTile{Solid: true}
+		drag     *Coord     // where a rectangle being filled started
+	)
+
+	var (
+		zoom        int  // the level is drawn 2 to the power of zoom times as big
+		grid        bool // lines are drawn between tiles
+		hideMinimap bool
+		jumping     bool // the mouse went down on the minimap and is moving the view
+		picking     bool // the mouse went down on the tool palette
+	)
+
+	// levels are saved in the format they were loaded in. new levels use
+	// the text format.
+	format := LevelFormat_Text
 	func() {
-		f, err := os.Open(filename)
-		if err == nil {
-			defer f.Close()
-			err = gob.NewDecoder(f).Decode(&world)
+		var err error
+		if addr == "" {
+			var b []byte
+			b, err = ioutil.ReadFile(filename)
+			if err == nil {
+				format = DetectLevelFormat(b)
+				var w *World
+				w, err = DecodeWorld(bytes.NewReader(b))
+				if err == nil {
+					world = *w
+				}
+			}
+		}
+		if err != nil && !os.IsNotExist(err) {
+			// don't let an empty level be saved over one that could not be
+			// read, which may be fixable by hand.
+			loadErr = err
+			status = fmt.Sprintf("could not load %s: %v", filename, err)
 		}
 		if err != nil || len(world.Tiles) < 3 {
 			world.Min = Coord{0, 0}
@@ -45,7 +102,276 @@ func Editor(filename string) {
 		}
 	}()
 
+	var (
+		outbox  chan<- *res.Packet // to the edit session, if there is one
+		me      = -1               // slot in the edit session
+		cursors = make(map[int]Coord)
+		sent    *Coord        // cursor position the edit session was last told
+		joining bool          // the edit session has not sent the level since joining
+		server  World         // the level as the edit session has it...
+		pending []*res.Packet // ...and the edits made here since, which have not come back
+		remote  uint64        // changes to the level that were not made here
+	)
+
+	// version identifies the level as it is after the edits made here and
+	// those made by anyone else in the edit session.
+	version := func() [2]uint64 {
+		return [2]uint64{history.version(), remote}
+	}
+
+	send := func(p *res.Packet) {
+		if outbox != nil {
+			outbox <- p
+		}
+	}
+
+	events := w.EventChan()
+	if addr != "" {
+		read, write, errors := make(chan *res.Packet), make(chan *res.Packet), make(chan error, 2)
+		go Reconnect(addr, read, write, errors)
+
+		ch := make(chan *res.Packet)
+		go Queue(ch, write)
+		defer close(ch)
+		outbox = ch
+
+		history.send = func(tiles []TileChange, objects *levelObjects) {
+			if len(tiles) != 0 {
+				p := &res.Packet{
+					Type: Type_EditTile,
+					Data: Encode(tiles),
+				}
+				pending = append(pending, p)
+				send(p)
+			}
+			if objects != nil {
+				p := &res.Packet{
+					Type: Type_EditObjects,
+					Data: Encode(*objects),
+				}
+				pending = append(pending, p)
+				send(p)
+			}
+		}
+
+		// packets and errors from the edit session come in between the
+		// window's events.
+		merged := make(chan interface{})
+		go func(window <-chan interface{}) {
+			for {
+				select {
+				case e, ok := <-window:
+					if !ok {
+						close(merged)
+						return
+					}
+					merged <- e
+				case p, ok := <-read:
+					if !ok {
+						read = nil
+						continue
+					}
+					merged <- p
+				case err, ok := <-errors:
+					if !ok {
+						errors = nil
+						continue
+					}
+					merged <- err
+				}
+			}
+		}(events)
+		events = merged
+
+		status = "connecting to " + addr
+	}
+
+	// rebase makes the level the one the edit session has with the edits
+	// that have not come back yet made again on top, as they will be in the
+	// edit session.
+	rebase := func() {
+		world.Min, world.Max = server.Min, server.Max
+		world.Tiles = append([]WorldTile(nil), server.Tiles...)
+		world.setObjects(server.objects())
+		world.rendered = nil
+		for _, p := range pending {
+			// an edit that cannot be made any more will not be made in
+			// the edit session either, which then sends the level again.
+			applyEdit(p, &world)
+		}
+	}
+
+	// network handles a packet or error from the edit session.
+	network := func(event interface{}) {
+		switch e := event.(type) {
+		case error:
+			status = "edit session: " + e.Error()
+
+		case *res.Packet:
+			switch e.GetType() {
+			case res.Type_Ping:
+				send(e)
+
+			case res.Type_SelectMan:
+				me, joining = int(e.GetSlot()), true
+
+			case res.Type_World:
+				level, err := DecodeWorld(bytes.NewReader(e.GetData()))
+				if err != nil {
+					status = "edit session sent a bad level: " + err.Error()
+					break
+				}
+				if joining {
+					// anything done before joining is gone, so it cannot
+					// be undone. edits sent before joining that come back
+					// are made like anyone else's.
+					server, pending, joining = *level, nil, false
+					rebase()
+					history = editHistory{send: history.send}
+					saved, selected, trigger, corner, drag, sent = version(), -1, -1, nil, nil, nil
+					status = "editing with " + addr
+					break
+				}
+
+				// the level is sent again in place of the oldest edit of
+				// ours that has not come back when it cannot be made.
+				history.end(&world)
+				history.share()
+				server = *level
+				if len(pending) != 0 {
+					pending = pending[1:]
+				}
+				rebase()
+				remote++
+				status = "edit session could not make an edit: it has been undone"
+				if selected >= len(world.Entities) {
+					selected = -1
+				}
+				if trigger >= len(world.Triggers) {
+					trigger = -1
+				}
+
+			case res.Type_EditTile, res.Type_EditObjects:
+				if err := applyEdit(e, &server); err != nil {
+					status = "edit session sent a bad edit: " + err.Error()
+					break
+				}
+				if int(e.GetSlot()) == me && len(pending) != 0 {
+					// ours, which was made here when it was sent.
+					pending = pending[1:]
+					break
+				}
+
+				// someone else's edit goes before ours that have not come
+				// back, so ours are made again after it. an edit being
+				// made here is sent now, so it is one of them.
+				history.end(&world)
+				history.share()
+				if len(pending) == 0 {
+					applyEdit(e, &world)
+				} else {
+					rebase()
+				}
+				remote++
+				// the entity or trigger being edited may be gone.
+				if selected >= len(world.Entities) {
+					selected = -1
+				}
+				if trigger >= len(world.Triggers) {
+					trigger = -1
+				}
+
+			case res.Type_EditSave:
+				status = "edit session saved the level"
+
+			case res.Type_EditCursor:
+				slot := int(e.GetSlot())
+				if e.X == nil || e.Y == nil {
+					delete(cursors, slot)
+				} else if slot != me {
+					cursors[slot] = Coord{e.GetX(), e.GetY()}
+				}
+			}
+		}
+	}
+
+	tileUnder := func(p image.Point) Coord {
+		width, height := w.Size()
+		// rounded the same way as the level is when it is drawn.
+		c := Coord{zoomed(int64(p.X-width/2)+zoomed(offX, zoom), -zoom), zoomed(int64(p.Y-height/2)+zoomed(offY, zoom), -zoom)}
+		c = c.Floor(TileSize)
+		return Coord{c.X / TileSize, c.Y / TileSize}
+	}
+	cursor := func() Coord {
+		return tileUnder(mouse)
+	}
+
+	// onMinimap returns the minimap and whether p is on it.
+	onMinimap := func(p image.Point) (minimap, bool) {
+		m := newMinimap(w.Screen().Bounds(), &world)
+		return m, !hideMinimap && p.In(m.Rect)
+	}
+
+	// jump moves the view to the tile under p on the minimap.
+	jump := func(m minimap, p image.Point) {
+		c := m.tile(p)
+		offX, offY = c.X*TileSize+TileSize/2, c.Y*TileSize+TileSize/2
+	}
+
+	useTool := func(t EditorTool) {
+		tool = t
+		if tool != Tool_Pick {
+			lastTool = tool
+		}
+		drag = nil
+	}
+
+	// paint is the tile a mouse button puts down with the current tool. the
+	// right button always erases.
+	paint := func(which wde.Button) WorldTile {
+		if tool == Tool_Erase || which&wde.RightButton != 0 {
+			return WorldTile{}
+		}
+		return brush
+	}
+
+	// save writes the level to name, unless it is the file that could not be
+	// loaded.
+	save := func(to string) {
+		history.end(&world)
+		if to == filename && loadErr != nil {
+			status = fmt.Sprintf("not saving over %s, which could not be loaded: %v (press Ctrl+Shift+S to save somewhere else)", to, loadErr)
+			return
+		}
+		if err := saveLevel(to, &world, format); err != nil {
+			status = "save failed: " + err.Error()
+			return
+		}
+		filename, loadErr, saved, closing = to, nil, version(), false
+		status = "saved " + to
+	}
+
+	// quit returns true if the editor can close without losing anything, or
+	// if it was told it could lose it by closing it twice.
+	quit := func() bool {
+		history.end(&world)
+		if version() != saved && (!closing || closeAt != version()) {
+			closing, closeAt = true, version()
+			status = "there are unsaved changes: close again to throw them away or press Ctrl+S to save"
+			return false
+		}
+		return true
+	}
+
 	render := func(offX, offY int64) {
+		t := "wdvn editor: " + filename
+		if version() != saved {
+			t += " (unsaved)"
+		}
+		if t != title {
+			title = t
+			w.SetTitle(title)
+		}
 		img := image.NewRGBA(w.Screen().Bounds())
 		gc := draw2d.NewGraphicContext(img)
 
@@ -54,94 +380,616 @@ func Editor(filename string) {
 
 		draw.Draw(img, img.Rect, image.White, image.ZP, draw.Src)
 
-		offX = int64(img.Rect.Dx()/2) - offX
-		offY = int64(img.Rect.Dy()/2) - offY
+		// the part of the level in the window, in pixels of the world.
+		view := image.Rect(int(offX-zoomed(int64(img.Rect.Dx()/2), -zoom)), int(offY-zoomed(int64(img.Rect.Dy()/2), -zoom)), 0, 0)
+		view.Max = view.Min.Add(image.Pt(int(zoomed(int64(img.Rect.Dx()), -zoom)), int(zoomed(int64(img.Rect.Dy()), -zoom))))
+
+		offX = int64(img.Rect.Dx()/2) - zoomed(offX, zoom)
+		offY = int64(img.Rect.Dy()/2) - zoomed(offY, zoom)
+
+		// z turns a distance in pixels of the world into one on the screen.
+		z := func(v int64) int64 {
+			return zoomed(v, zoom)
+		}
+
+		world.RenderZoom(img, offX, offY, zoom)
+
+		if grid {
+			renderGrid(img, offX, offY, z(TileSize))
+		}
+
+		// everyone else in the edit session.
+		for slot, c := range cursors {
+			fill := image.NewUniform(ManData[res.Man(slot%int(res.Man_count))].Color)
+			r := image.Rect(int(offX+z(c.X*TileSize)), int(offY+z(c.Y*TileSize)), int(offX+z(c.X*TileSize+TileSize)), int(offY+z(c.Y*TileSize+TileSize)))
+			draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+2), fill, image.ZP, draw.Src)
+			draw.Draw(img, image.Rect(r.Min.X, r.Max.Y-2, r.Max.X, r.Max.Y), fill, image.ZP, draw.Src)
+			draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+2, r.Max.Y), fill, image.ZP, draw.Src)
+			draw.Draw(img, image.Rect(r.Max.X-2, r.Min.Y, r.Max.X, r.Max.Y), fill, image.ZP, draw.Src)
+		}
+
+		for i := range world.Entities {
+			e := &world.Entities[i]
+			if len(e.Path) == 0 {
+				continue
+			}
+			ex, ey := offX+z(e.Path[0].X*TileSize), offY+z(e.Path[0].Y*TileSize)
+			if zoom == 0 {
+				renderEntity(img, &world, e, ex, ey)
+			} else {
+				// entities are not in the render cache, so each one is
+				// zoomed by itself.
+				sprite := image.NewRGBA(image.Rect(0, 0, int(e.Size.X*TileSize), int(e.Size.Y*TileSize)))
+				renderEntity(sprite, &world, e, 0, 0)
+				sprite = scaleImage(sprite, zoom)
+				draw.Draw(img, sprite.Rect.Add(image.Pt(int(ex), int(ey))), sprite, image.ZP, draw.Over)
+			}
+
+			gc.MoveTo(float64(ex), float64(ey))
+			for _, p := range e.Path[1:] {
+				gc.LineTo(float64(offX+z(p.X*TileSize)), float64(offY+z(p.Y*TileSize)))
+			}
+			if e.Kind == Entity_Platform {
+				gc.LineTo(float64(ex), float64(ey))
+			}
+			gc.Stroke()
 
-		world.Render(img, offX, offY)
+			label := entityKind_names[e.Kind]
+			if i == selected {
+				label = "> " + label
+			}
+			if e.Kind == Entity_Door {
+				label += fmt.Sprintf(" (switch %d,%d)", e.Switch.X, e.Switch.Y)
+			}
+			gc.StrokeStringAt(label, float64(ex), float64(ey-2))
+			gc.FillStringAt(label, float64(ex), float64(ey-2))
+		}
+
+		for i := range world.Triggers {
+			t := &world.Triggers[i]
+			x0, y0 := float64(offX+z(t.Min.X*TileSize)), float64(offY+z(t.Min.Y*TileSize))
+			x1, y1 := float64(offX+z(t.Max.X*TileSize+TileSize)), float64(offY+z(t.Max.Y*TileSize+TileSize))
+			gc.MoveTo(x0, y0)
+			gc.LineTo(x1, y0)
+			gc.LineTo(x1, y1)
+			gc.LineTo(x0, y1)
+			gc.LineTo(x0, y0)
+			gc.Stroke()
+
+			label := "on " + triggerEvent_names[t.Event]
+			if i == trigger {
+				label = "> " + label
+			}
+			gc.StrokeStringAt(label, x0+2, y0+12)
+			gc.FillStringAt(label, x0+2, y0+12)
 
-		for x := world.Min.X; x <= world.Max.X; x++ {
+			for _, a := range t.Actions {
+				at := a.At
+				if a.Kind == Action_OpenDoor && a.Door >= 0 && a.Door < len(world.Entities) && len(world.Entities[a.Door].Path) != 0 {
+					at = world.Entities[a.Door].Path[0]
+				}
+				ax, ay := float64(offX+z(at.X*TileSize+TileSize/2)), float64(offY+z(at.Y*TileSize+TileSize/2))
+				gc.MoveTo((x0+x1)/2, (y0+y1)/2)
+				gc.LineTo(ax, ay)
+				gc.Stroke()
+				label := actionKind_names[a.Kind]
+				if a.Kind == Action_Floater {
+					label += " " + a.Text
+				}
+				gc.StrokeStringAt(label, ax, ay)
+				gc.FillStringAt(label, ax, ay)
+			}
+		}
+
+		// zoomed out, the names of specials would cover the tiles.
+		for x := world.Min.X; zoom >= 0 && x <= world.Max.X; x++ {
 			for y := world.Min.Y; y <= world.Max.Y; y++ {
 				if s := world.Special(x, y); s != SpecialTile_None {
-					gc.StrokeStringAt(specialTile_names[s], float64(offX+x*TileSize), float64(offY+y*TileSize+TileSize))
-					gc.FillStringAt(specialTile_names[s], float64(offX+x*TileSize), float64(offY+y*TileSize+TileSize))
+					gc.StrokeStringAt(specialTile_names[s], float64(offX+z(x*TileSize)), float64(offY+z(y*TileSize+TileSize)))
+					gc.FillStringAt(specialTile_names[s], float64(offX+z(x*TileSize)), float64(offY+z(y*TileSize+TileSize)))
 				}
 			}
 		}
 
+		sx, sy := float64(offX+z(world.Meta.Spawn.X/PixelSize)), float64(offY+z(world.Meta.Spawn.Y/PixelSize))
+		gc.MoveTo(sx, sy)
+		gc.LineTo(sx, sy-TileSize)
+		gc.Stroke()
+		gc.StrokeStringAt("spawn", sx+2, sy-2)
+		gc.FillStringAt("spawn", sx+2, sy-2)
+
+		if drag != nil {
+			min, max := tileRect(*drag, cursor())
+			x0, y0 := float64(offX+z(min.X*TileSize)), float64(offY+z(min.Y*TileSize))
+			x1, y1 := float64(offX+z(max.X*TileSize+TileSize)), float64(offY+z(max.Y*TileSize+TileSize))
+			gc.MoveTo(x0, y0)
+			gc.LineTo(x1, y0)
+			gc.LineTo(x1, y1)
+			gc.LineTo(x0, y1)
+			gc.LineTo(x0, y0)
+			gc.Stroke()
+		}
+
+		if grid {
+			c := cursor()
+			label := fmt.Sprintf("%d,%d", c.X, c.Y)
+			gc.StrokeStringAt(label, float64(mouse.X+12), float64(mouse.Y))
+			gc.FillStringAt(label, float64(mouse.X+12), float64(mouse.Y))
+		}
+
+		if !hideMinimap {
+			newMinimap(img.Rect, &world).render(img, &world, view)
+		}
+
+		draw.Draw(img, image.Rect(2, 2, TileSize+6, TileSize+6), image.Black, image.ZP, draw.Src)
+		draw.Draw(img, image.Rect(3, 3, TileSize+5, TileSize+5), image.White, image.ZP, draw.Src)
+		renderTile(img, image.Rect(4, 4, TileSize+4, TileSize+4), world.Terrain(), brush)
+
+		label := fmt.Sprintf("%v: tile %d", tool, brush.Tile)
+		if brush.Solid {
+			label += ", " + tileShape_names[brush.Shape]
+		} else {
+			label += ", not solid"
+		}
+		if brush.SpecialTile != SpecialTile_None {
+			label += ", " + specialTile_names[brush.SpecialTile]
+		}
+		gc.StrokeStringAt(label, TileSize+10, 14)
+		gc.FillStringAt(label, TileSize+10, 14)
+
+		for t := EditorTool(0); t < Tool_count; t++ {
+			r := paletteRect(t)
+			fill := image.White
+			if t == tool {
+				fill = image.NewUniform(color.RGBA{255, 224, 128, 255})
+			}
+			draw.Draw(img, r, image.Black, image.ZP, draw.Src)
+			draw.Draw(img, r.Inset(1), fill, image.ZP, draw.Src)
+			label := fmt.Sprintf("%d %v", t+1, t)
+			gc.StrokeStringAt(label, float64(r.Min.X+4), float64(r.Max.Y-5))
+			gc.FillStringAt(label, float64(r.Min.X+4), float64(r.Max.Y-5))
+		}
+
+		if naming {
+			gc.StrokeStringAt("save as: "+name+"_", TileSize+10, 28)
+			gc.FillStringAt("save as: "+name+"_", TileSize+10, 28)
+		} else if status != "" {
+			gc.StrokeStringAt(status, TileSize+10, 28)
+			gc.FillStringAt(status, TileSize+10, 28)
+		}
+
 		w.Screen().CopyRGBA(img, img.Rect)
 		w.FlushImage(img.Rect)
 	}
 
+	// addEntity places a new entity at the cursor and selects it.
+	addEntity := func(kind EntityKind, size Coord) {
+		world.Entities = append(world.Entities, Entity{
+			Kind:  kind,
+			Size:  size,
+			Path:  []Coord{cursor()},
+			Speed: EntitySpeed,
+		})
+		selected = len(world.Entities) - 1
+	}
+
+	// addAction gives the selected trigger another action.
+	addAction := func(a Action) {
+		if trigger >= 0 {
+			world.Triggers[trigger].Actions = append(world.Triggers[trigger].Actions, a)
+		}
+	}
+
 	render(offX, offY)
-	for event := range w.EventChan() {
+	for event := range events {
 		switch e := event.(type) {
+		case *res.Packet, error:
+			network(e)
 		case wde.CloseEvent:
-			f, err := os.Create(filename)
-			if err != nil {
-				panic(err)
+			if quit() {
+				return
 			}
-			defer f.Close()
-			err = gob.NewEncoder(f).Encode(&world)
-			return
 		case wde.ResizeEvent:
 			// do nothing
 		case wde.KeyDownEvent:
-			// TODO
+			switch e.Key {
+			case wde.KeyLeftControl, wde.KeyRightControl:
+				ctrl = true
+			case wde.KeyLeftShift, wde.KeyRightShift:
+				shift = true
+			}
 		case wde.KeyTypedEvent:
+			if naming {
+				switch e.Key {
+				case wde.KeyReturn:
+					naming = false
+					if name != "" {
+						save(name)
+					}
+				case wde.KeyEscape:
+					naming = false
+				case wde.KeyBackspace:
+					if name != "" {
+						name = name[:len(name)-1]
+					}
+				default:
+					name += e.Glyph
+				}
+				break
+			}
+			if ctrl {
+				switch e.Key {
+				case wde.KeyS:
+					if shift {
+						naming, name = true, filename
+					} else {
+						save(filename)
+						// the edit session saves its own copy too.
+						send(&res.Packet{Type: Type_EditSave})
+					}
+				case wde.KeyZ:
+					history.Undo(&world)
+				case wde.KeyY:
+					history.Redo(&world)
+				}
+				// the entity or trigger being edited may be gone.
+				if selected >= len(world.Entities) {
+					selected = -1
+				}
+				if trigger >= len(world.Triggers) {
+					trigger = -1
+				}
+				break
+			}
+
+			// every other key is its own edit.
+			history.begin(&world)
+
 			switch e.Key {
 			case wde.KeyUpArrow:
-				offY -= 10
+				offY -= zoomed(10, -zoom)
 			case wde.KeyDownArrow:
-				offY += 10
+				offY += zoomed(10, -zoom)
 			case wde.KeyLeftArrow:
-				offX -= 10
+				offX -= zoomed(10, -zoom)
 			case wde.KeyRightArrow:
-				offX += 10
+				offX += zoomed(10, -zoom)
+			case wde.KeyL:
+				grid = !grid
+			case wde.KeyM:
+				hideMinimap = !hideMinimap
+			case wde.Key1, wde.Key2, wde.Key3, wde.Key4, wde.Key5, wde.Key6:
+				useTool(EditorTool(e.Key[0] - '1'))
+			case wde.KeyLeftBracket:
+				brush.Shape = (brush.Shape + TileShape_count - 1) % TileShape_count
+			case wde.KeyRightBracket:
+				brush.Shape = (brush.Shape + 1) % TileShape_count
+			case wde.KeyComma:
+				brush.Tile = (brush.Tile + len(world.Terrain()) - 1) % len(world.Terrain())
+			case wde.KeyPeriod:
+				brush.Tile = (brush.Tile + 1) % len(world.Terrain())
+			case wde.KeyB:
+				// liquid is the only special a tile can have without being
+				// solid.
+				brush.Solid = !brush.Solid
+				if brush.Solid == (brush.SpecialTile == SpecialTile_Liquid) {
+					brush.SpecialTile = SpecialTile_None
+				}
+			case wde.KeyV:
+				brush.SpecialTile = (brush.SpecialTile + 1) % SpecialTile_count
+				brush.Solid = brush.SpecialTile != SpecialTile_Liquid
+
+			case wde.KeyP:
+				addEntity(Entity_Platform, Coord{3, 1})
+			case wde.KeyD:
+				addEntity(Entity_Door, Coord{1, 3})
+			case wde.KeyC:
+				addEntity(Entity_Crusher, Coord{3, 2})
+			case wde.KeyN:
+				// next waypoint for the selected entity, which has to be
+				// somewhere else for the entity to go anywhere.
+				if selected >= 0 {
+					e := &world.Entities[selected]
+					if c := cursor(); len(e.Path) == 0 || e.Path[len(e.Path)-1] != c {
+						e.Path = append(e.Path, c)
+					}
+				}
+			case wde.KeyK:
+				// wire the selected door to a switch under the cursor
+				if selected >= 0 && world.Entities[selected].Kind == Entity_Door {
+					c := cursor()
+					t := world.at(c)
+					t.Solid = true
+					t.SpecialTile = SpecialTile_Switch
+					history.set(&world, c, t)
+					world.Entities[selected].Switch = c
+				}
+			case wde.KeyEqual:
+				if selected >= 0 {
+					world.Entities[selected].Speed += EntitySpeed / 4
+				}
+			case wde.KeyMinus:
+				if selected >= 0 && world.Entities[selected].Speed > EntitySpeed/4 {
+					world.Entities[selected].Speed -= EntitySpeed / 4
+				}
+			case wde.KeyTab:
+				// select the next entity
+				if len(world.Entities) != 0 {
+					selected = (selected + 1) % len(world.Entities)
+				}
+			case wde.KeyX:
+				if selected >= 0 {
+					world.Entities = append(world.Entities[:selected], world.Entities[selected+1:]...)
+					// doors after this one move down a place.
+					for i := range world.Triggers {
+						actions := world.Triggers[i].Actions[:0]
+						for _, a := range world.Triggers[i].Actions {
+							if a.Kind == Action_OpenDoor && a.Door == selected {
+								continue
+							}
+							if a.Kind == Action_OpenDoor && a.Door > selected {
+								a.Door--
+							}
+							actions = append(actions, a)
+						}
+						world.Triggers[i].Actions = actions
+					}
+					selected = -1
+				}
+
+			case wde.KeyR:
+				// the first press marks one corner of a trigger region and
+				// the second press the other.
+				c := cursor()
+				if corner == nil {
+					corner = &c
+					break
+				}
+				min, max := *corner, c
+				if min.X > max.X {
+					min.X, max.X = max.X, min.X
+				}
+				if min.Y > max.Y {
+					min.Y, max.Y = max.Y, min.Y
+				}
+				world.Triggers = append(world.Triggers, Trigger{Min: min, Max: max})
+				trigger = len(world.Triggers) - 1
+				corner = nil
+			case wde.KeyE:
+				if trigger >= 0 {
+					world.Triggers[trigger].Event = (world.Triggers[trigger].Event + 1) % TriggerEvent_count
+				}
+			case wde.KeyT:
+				if len(world.Triggers) != 0 {
+					trigger = (trigger + 1) % len(world.Triggers)
+				}
+			case wde.KeyDelete:
+				if trigger >= 0 {
+					world.Triggers = append(world.Triggers[:trigger], world.Triggers[trigger+1:]...)
+					trigger = -1
+				}
+			case wde.KeyO:
+				if selected >= 0 && world.Entities[selected].Kind == Entity_Door {
+					addAction(Action{Kind: Action_OpenDoor, Door: selected})
+				}
+			case wde.KeyG:
+				addAction(Action{Kind: Action_SpawnUnit, At: cursor()})
+			case wde.KeyF:
+				addAction(Action{Kind: Action_Floater, At: cursor(), Text: "!"})
+			case wde.KeyH:
+				addAction(Action{Kind: Action_Checkpoint, At: cursor()})
+
+			case wde.KeyS:
+				// mans start standing on the bottom of the tile.
+				world.Meta.Spawn = tileBottom(cursor())
+				world.Meta.Spawn.Y++
+
+			case wde.KeyReturn:
+				// play from the spawn point, or from the cursor with shift.
+				start := world.Meta.Spawn
+				if shift {
+					start = tileBottom(cursor())
+					start.Y++
+				}
+				history.end(&world)
+				ok := playtest(w, events, network, &world, start)
+				// the keys may have been let go while playing.
+				ctrl, shift = false, false
+				if !ok && quit() {
+					return
+				}
 			}
+
+			history.end(&world)
 		case wde.KeyUpEvent:
-			// TODO
+			switch e.Key {
+			case wde.KeyLeftControl, wde.KeyRightControl:
+				ctrl = false
+			case wde.KeyLeftShift, wde.KeyRightShift:
+				shift = false
+			}
 		case wde.MouseDownEvent:
-			width, height := w.Size()
-			c := Coord{offX + int64(e.Where.X-width/2), offY + int64(e.Where.Y-height/2)}
-			c = c.Floor(TileSize)
-			world.ensureTileExists(c.X/TileSize, c.Y/TileSize)
-			i, _ := world.index(c.X/TileSize, c.Y/TileSize)
+			mouse = e.Where
+
+			if e.Which == wde.WheelUpButton || e.Which == wde.WheelDownButton {
+				// the tile under the mouse stays under it.
+				width, height := w.Size()
+				dx, dy := int64(mouse.X-width/2), int64(mouse.Y-height/2)
+				x, y := offX+zoomed(dx, -zoom), offY+zoomed(dy, -zoom)
+				if e.Which == wde.WheelUpButton && zoom < EditorZoomMax {
+					zoom++
+				}
+				if e.Which == wde.WheelDownButton && zoom > EditorZoomMin {
+					zoom--
+				}
+				offX, offY = x-zoomed(dx, -zoom), y-zoomed(dy, -zoom)
+				break
+			}
+			if m, ok := onMinimap(mouse); ok {
+				jumping = true
+				jump(m, mouse)
+				break
+			}
+			if t, ok := paletteTool(mouse); ok {
+				picking = true
+				useTool(t)
+				break
+			}
+
+			c := cursor()
+
+			// the edit lasts until the button comes back up, so a drag
+			// is undone all at once.
+			history.begin(&world)
+
+			if tool != Tool_Edit {
+				switch {
+				case tool == Tool_Pick || e.Which == wde.MiddleButton:
+					brush = world.at(c)
+					if tool == Tool_Pick {
+						tool = lastTool
+					}
+				case tool == Tool_Rect:
+					drag = &c
+				case tool == Tool_Fill:
+					t := paint(e.Which)
+					for _, f := range world.floodRegion(c) {
+						history.set(&world, f, t)
+					}
+				default:
+					history.set(&world, c, paint(e.Which))
+				}
+				break
+			}
+
+			t := world.at(c)
 
 			switch e.Which {
 			case wde.LeftButton:
-				if world.Tiles[i].Solid {
-					world.Tiles[i].Tile += 1
-					world.Tiles[i].Tile %= len(terrain)
+				if t.Solid {
+					t.Tile += 1
+					t.Tile %= len(world.Terrain())
 				}
 			case wde.MiddleButton:
-				if world.Tiles[i].Solid {
-					world.Tiles[i].SpecialTile++
-					world.Tiles[i].SpecialTile %= SpecialTile_count
+				if t.Solid {
+					t.SpecialTile++
+					t.SpecialTile %= SpecialTile_count
+					if t.SpecialTile == SpecialTile_Liquid {
+						t.SpecialTile = SpecialTile_None
+					}
+				} else if t.SpecialTile == SpecialTile_Liquid {
+					t.SpecialTile = SpecialTile_None
+				} else {
+					t.SpecialTile = SpecialTile_Liquid
 				}
 			case wde.RightButton:
-				if world.Tiles[i].Solid {
-					world.Tiles[i] = WorldTile{}
+				if t.Solid && t.Shape != brush.Shape {
+					t.Shape = brush.Shape
+				} else if t.Solid {
+					t = WorldTile{}
 				} else {
-					world.Tiles[i].Solid = true
+					t.Solid = true
+					t.Shape = brush.Shape
+					t.SpecialTile = SpecialTile_None
 				}
 			}
 
-			world.shrink()
-
-			world.rendered = nil
+			history.set(&world, c, t)
 		case wde.MouseUpEvent:
-			// TODO
+			mouse = e.Where
+			if e.Which == wde.WheelUpButton || e.Which == wde.WheelDownButton {
+				break
+			}
+			if jumping || picking {
+				jumping, picking = false, false
+				break
+			}
+			if drag != nil {
+				min, max := tileRect(*drag, cursor())
+				world.ensureTileExists(min.X, min.Y)
+				world.ensureTileExists(max.X, max.Y)
+				t := paint(e.Which)
+				for x := min.X; x <= max.X; x++ {
+					for y := min.Y; y <= max.Y; y++ {
+						history.set(&world, Coord{x, y}, t)
+					}
+				}
+				drag = nil
+			}
+
+			history.end(&world)
 		case wde.MouseEnteredEvent:
 			// TODO
 		case wde.MouseExitedEvent:
 			// TODO
 		case wde.MouseMovedEvent:
-			// TODO
+			mouse = e.Where
 		case wde.MouseDraggedEvent:
-			// TODO
+			mouse = e.Where
+			if jumping {
+				m, _ := onMinimap(mouse)
+				jump(m, mouse)
+				break
+			}
+			if picking {
+				break
+			}
+			if tool == Tool_Paint || tool == Tool_Erase {
+				t := paint(e.Which)
+				for _, c := range tileLine(tileUnder(e.From), cursor()) {
+					history.set(&world, c, t)
+				}
+			}
 		default:
 			panic(fmt.Errorf("unexpected event type %T in %#v", event, event))
 		}
+
+		if c := cursor(); outbox != nil && (sent == nil || *sent != c) {
+			sent = &c
+			send(&res.Packet{
+				Type: Type_EditCursor,
+				X:    proto.Int64(c.X),
+				Y:    proto.Int64(c.Y),
+			})
+		}
+
 		render(offX, offY)
 	}
 }
+
+// EditorBackupSuffix is added to the name of a level to get the name of the
+// copy kept from before it was last saved.
+const EditorBackupSuffix = ".bak"
+
+// saveLevel writes w to filename. The level is written to a temporary file
+// first, so a failed save leaves the old one alone, and the old one is then
+// kept as a backup.
+func saveLevel(filename string, w *World, format LevelFormat) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(filename); err == nil {
+		mode = fi.Mode()
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".")
+	if err != nil {
+		return err
+	}
+	err = EncodeWorld(f, w, format)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(filename, filename+EditorBackupSuffix)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,245 @@
+package main
+
+import "reflect"
+
+// EditorHistorySize is how many edits the editor can undo.
+const EditorHistorySize = 100
+
+// tileEdit is one tile being replaced.
+type tileEdit struct {
+	At            Coord
+	Before, After WorldTile
+}
+
+// levelObjects is everything in a level except the tiles. It is small enough
+// to copy whole for every edit.
+type levelObjects struct {
+	Entities []Entity
+	Triggers []Trigger
+	Meta     LevelMeta
+}
+
+// copy returns a copy of o that later changes to o do not affect.
+func (o levelObjects) copy() levelObjects {
+	o.Entities = append([]Entity(nil), o.Entities...)
+	o.Triggers = append([]Trigger(nil), o.Triggers...)
+	for i := range o.Entities {
+		o.Entities[i].Path = append([]Coord(nil), o.Entities[i].Path...)
+	}
+	for i := range o.Triggers {
+		o.Triggers[i].Actions = append([]Action(nil), o.Triggers[i].Actions...)
+	}
+	o.Meta.Parallax = append([]string(nil), o.Meta.Parallax...)
+	return o
+}
+
+func (w *World) objects() levelObjects {
+	return levelObjects{w.Entities, w.Triggers, w.Meta}.copy()
+}
+
+func (w *World) setObjects(o levelObjects) {
+	o = o.copy()
+	if o.Meta.Tileset != w.Meta.Tileset {
+		w.rendered = nil
+	}
+	w.Entities, w.Triggers, w.Meta = o.Entities, o.Triggers, o.Meta
+}
+
+// edit is one undoable change to a level. The tiles are kept in the order
+// they were set. The level only grows while an edit is being made and is
+// shrunk once at the end, so playing the tiles back from the bounds before
+// the edit gives the same level, rows thrown away by shrink and all. That
+// stops being true once someone else in an edit session has changed the
+// level, so shared edits are undone one tile at a time instead.
+type edit struct {
+	min, max           Coord // bounds before the edit
+	grownMin, grownMax Coord // bounds after the edit, before shrinking
+	tiles              []tileEdit
+	before, after      levelObjects
+	objects            bool // before and after are different
+	shared             bool // the level was changed by someone else since
+	id                 uint64
+}
+
+// editHistory is the undo and redo stacks of the editor.
+type editHistory struct {
+	undo, redo []*edit
+	current    *edit
+	last       uint64 // id of the newest edit
+	base       uint64 // id of the edit before the oldest that can be undone
+
+	// send, if set, is told about every tile set by finishing, undoing,
+	// or redoing an edit, in order, and about the rest of the level if it
+	// changed, so it can be shared with an edit session.
+	send func(tiles []TileChange, objects *levelObjects)
+}
+
+// begin starts recording an edit to w, finishing any edit already started.
+func (h *editHistory) begin(w *World) {
+	h.end(w)
+	h.current = &edit{
+		min:    w.Min,
+		max:    w.Max,
+		before: w.objects(),
+	}
+}
+
+// set replaces the tile at c as part of the current edit.
+func (h *editHistory) set(w *World, c Coord, t WorldTile) {
+	if h.current == nil {
+		h.begin(w)
+	}
+	w.ensureTileExists(c.X, c.Y)
+	i, _ := w.index(c.X, c.Y)
+	h.current.tiles = append(h.current.tiles, tileEdit{c, w.Tiles[i], t})
+	w.Tiles[i] = t
+	w.redraw(c)
+}
+
+// end finishes the current edit, shrinking w and remembering the edit if it
+// changed anything.
+func (h *editHistory) end(w *World) {
+	e := h.current
+	if e == nil {
+		return
+	}
+	h.current = nil
+
+	e.grownMin, e.grownMax = w.Min, w.Max
+	w.shrink()
+	e.after = w.objects()
+
+	e.objects = !reflect.DeepEqual(e.before, e.after)
+	// setting a tile to what it already was can still change the bounds,
+	// so the edit session is told about it anyway.
+	if h.send != nil && (len(e.tiles) != 0 || e.objects) {
+		tiles := make([]TileChange, len(e.tiles))
+		for i, t := range e.tiles {
+			tiles[i] = TileChange{t.At, t.After}
+		}
+		h.sendEdit(tiles, e.objects, e.after)
+	}
+
+	changed := e.objects
+	for _, t := range e.tiles {
+		if t.Before != t.After {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	h.last++
+	e.id = h.last
+	h.undo = append(h.undo, e)
+	if len(h.undo) > EditorHistorySize {
+		h.base = h.undo[0].id
+		h.undo = append(h.undo[:0], h.undo[1:]...)
+	}
+	h.redo = nil
+}
+
+func (h *editHistory) sendEdit(tiles []TileChange, objects bool, o levelObjects) {
+	if objects {
+		h.send(tiles, &o)
+	} else {
+		h.send(tiles, nil)
+	}
+}
+
+// share marks every edit as made before someone else in an edit session
+// changed the level.
+func (h *editHistory) share() {
+	for _, e := range h.undo {
+		e.shared = true
+	}
+	for _, e := range h.redo {
+		e.shared = true
+	}
+	if h.current != nil {
+		h.current.shared = true
+	}
+}
+
+// version identifies the level as it is after the edits that have not been
+// undone, so the editor can tell whether it has been saved.
+func (h *editHistory) version() uint64 {
+	if len(h.undo) == 0 {
+		return h.base
+	}
+	return h.undo[len(h.undo)-1].id
+}
+
+// Undo reverts the last edit to w. It returns false if there was nothing to
+// undo.
+func (h *editHistory) Undo(w *World) bool {
+	h.end(w)
+	if len(h.undo) == 0 {
+		return false
+	}
+	e := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+
+	// the rest of an edit session only sees the tiles being set, so they
+	// are all this can do if there is one.
+	if e.shared || h.send != nil {
+		for i := len(e.tiles) - 1; i >= 0; i-- {
+			w.setTile(e.tiles[i].At, e.tiles[i].Before)
+		}
+		w.shrink()
+	} else {
+		// growing back to the bounds before shrinking gives exactly the
+		// tiles there were at the end of the edit, which are then set
+		// back in reverse.
+		w.resize(e.grownMin, e.grownMax)
+		for i := len(e.tiles) - 1; i >= 0; i-- {
+			j, _ := w.index(e.tiles[i].At.X, e.tiles[i].At.Y)
+			w.Tiles[j] = e.tiles[i].Before
+		}
+		w.resize(e.min, e.max)
+		for _, t := range e.tiles {
+			w.redraw(t.At)
+		}
+	}
+	w.setObjects(e.before)
+
+	if h.send != nil {
+		tiles := make([]TileChange, len(e.tiles))
+		for i, t := range e.tiles {
+			tiles[len(tiles)-1-i] = TileChange{t.At, t.Before}
+		}
+		h.sendEdit(tiles, e.objects, e.before)
+	}
+
+	h.redo = append(h.redo, e)
+	return true
+}
+
+// Redo makes the last undone edit to w again. It returns false if there was
+// nothing to redo.
+func (h *editHistory) Redo(w *World) bool {
+	h.end(w)
+	if len(h.redo) == 0 {
+		return false
+	}
+	e := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+
+	for _, t := range e.tiles {
+		w.setTile(t.At, t.After)
+	}
+	w.shrink()
+	w.setObjects(e.after)
+
+	if h.send != nil {
+		tiles := make([]TileChange, len(e.tiles))
+		for i, t := range e.tiles {
+			tiles[i] = TileChange{t.At, t.After}
+		}
+		h.sendEdit(tiles, e.objects, e.after)
+	}
+
+	h.undo = append(h.undo, e)
+	return true
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"code.google.com/p/goprotobuf/proto"
+	"github.com/Rnoadm/wdvn/res"
+	"github.com/skelterjohn/go.wde"
+	"image"
+	"image/color"
+	"time"
+)
+
+// playtest plays world in the editor's window, with one man on the keyboard
+// that starts at start, until Escape is pressed. It returns false if the
+// window was closed instead. events are the window's events, which may have
+// packets and errors from an edit session in between that are passed to
+// network.
+func playtest(w wde.Window, events <-chan interface{}, network func(interface{}), world *World, start Coord) bool {
+	// the world is only read while playing, so the tiles and the render
+	// cache are shared with the editor.
+	play := *world
+	play.Meta.Spawn = start
+
+	state := NewState(&play)
+	state.Mans = state.Mans[res.Man_Normal : res.Man_Normal+1]
+	state.Scores = state.Scores[res.Man_Normal : res.Man_Normal+1]
+	state.Stats = state.Stats[res.Man_Normal : res.Man_Normal+1]
+
+	var input res.Packet
+	key := func(k string, b *res.Button) {
+		switch k {
+		case wde.KeyW, wde.KeyPadUp, wde.KeyUpArrow, wde.KeySpace:
+			input.KeyUp = b
+		case wde.KeyS, wde.KeyPadDown, wde.KeyDownArrow:
+			input.KeyDown = b
+		case wde.KeyA, wde.KeyPadLeft, wde.KeyLeftArrow:
+			input.KeyLeft = b
+		case wde.KeyD, wde.KeyPadRight, wde.KeyRightArrow:
+			input.KeyRight = b
+		}
+	}
+	mouse := func(where image.Point) {
+		p := Mouse(w, state, 0, where)
+		input.X, input.Y = p.X, p.Y
+	}
+
+	tick := time.NewTicker(time.Second / TicksPerSecond)
+	defer tick.Stop()
+
+	img := image.NewRGBA(w.Screen().Bounds())
+	for {
+		select {
+		case <-tick.C:
+			state.Update([]res.Packet{input})
+
+			if img.Rect != w.Screen().Bounds() {
+				img = image.NewRGBA(w.Screen().Bounds())
+			}
+			Render(img, 0, state, nil)
+			RenderText(img, "playtesting: press Escape to go back to editing", image.Pt((img.Rect.Min.X+img.Rect.Max.X)/2, img.Rect.Max.Y-6), color.Black, color.White, true)
+			w.Screen().CopyRGBA(img, img.Rect)
+			w.FlushImage(img.Rect)
+
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			switch e := event.(type) {
+			case *res.Packet, error:
+				network(e)
+			case wde.CloseEvent:
+				return false
+			case wde.KeyDownEvent:
+				switch e.Key {
+				case wde.KeyEscape:
+					return true
+				case wde.KeyF1:
+					state.SetMan(0, res.Man_Whip)
+				case wde.KeyF2:
+					state.SetMan(0, res.Man_Density)
+				case wde.KeyF3:
+					state.SetMan(0, res.Man_Vacuum)
+				case wde.KeyF4:
+					state.SetMan(0, res.Man_Normal)
+				case wde.KeyF5:
+					state.SetMan(0, res.Man_Portal)
+				default:
+					key(e.Key, Button_pressed)
+				}
+			case wde.KeyUpEvent:
+				key(e.Key, Button_released)
+			case wde.MouseDownEvent:
+				mouse(e.Where)
+				switch e.Which {
+				case wde.LeftButton:
+					input.Mouse1 = Button_pressed
+				case wde.RightButton:
+					input.Mouse2 = Button_pressed
+				}
+			case wde.MouseUpEvent:
+				mouse(e.Where)
+				switch e.Which {
+				case wde.LeftButton:
+					input.Mouse1 = Button_released
+				case wde.RightButton:
+					input.Mouse2 = Button_released
+				}
+			case wde.MouseMovedEvent:
+				mouse(e.Where)
+			case wde.MouseDraggedEvent:
+				mouse(e.Where)
+			case wde.MouseExitedEvent:
+				input = res.Packet{}
+				proto.Merge(&input, ReleaseAll)
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+)
+
+// EditorTool is what the mouse does to tiles in the editor.
+type EditorTool int
+
+const (
+	Tool_Edit  EditorTool = iota // each click cycles the tile, special, or shape of one tile
+	Tool_Paint                   // paints the brush while dragging
+	Tool_Rect                    // fills a dragged rectangle with the brush
+	Tool_Fill                    // fills the tiles connected to the clicked one with the brush
+	Tool_Erase                   // clears tiles while dragging
+	Tool_Pick                    // copies the clicked tile into the brush
+	Tool_count
+)
+
+var editorTool_names [Tool_count]string = [...]string{
+	Tool_Edit:  "edit",
+	Tool_Paint: "paint",
+	Tool_Rect:  "rectangle",
+	Tool_Fill:  "fill",
+	Tool_Erase: "erase",
+	Tool_Pick:  "pick",
+}
+
+func (t EditorTool) String() string {
+	return editorTool_names[t]
+}
+
+// The tool palette is a row of buttons under the brush, one for each tool,
+// labelled with the number key that also picks it.
+const (
+	EditorPaletteTop    = 34
+	EditorPaletteWidth  = 76 // of each button
+	EditorPaletteHeight = 18
+)
+
+// paletteRect returns where the button for t is.
+func paletteRect(t EditorTool) image.Rectangle {
+	x := 2 + int(t)*(EditorPaletteWidth+2)
+	return image.Rect(x, EditorPaletteTop, x+EditorPaletteWidth, EditorPaletteTop+EditorPaletteHeight)
+}
+
+// paletteTool returns the tool whose button is at p, if there is one.
+func paletteTool(p image.Point) (EditorTool, bool) {
+	for t := EditorTool(0); t < Tool_count; t++ {
+		if p.In(paletteRect(t)) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// at returns the tile at c, as it would be if the world were big enough to
+// have one there.
+func (w *World) at(c Coord) WorldTile {
+	i, _ := w.index(c.X, c.Y)
+	return w.Tiles[i]
+}
+
+// setTile replaces the tile at c, growing the world if needed. The caller is
+// expected to shrink the world when it is done editing.
+func (w *World) setTile(c Coord, t WorldTile) {
+	w.ensureTileExists(c.X, c.Y)
+	i, _ := w.index(c.X, c.Y)
+	w.Tiles[i] = t
+	w.redraw(c)
+}
+
+// floodRegion returns the tiles connected to start that are the same as it.
+// Past the edge of the level, tiles repeat forever, so the search stops at
+// the smallest rectangle holding the level and start.
+func (w *World) floodRegion(start Coord) []Coord {
+	min, max := w.Min, w.Max
+	if start.X < min.X {
+		min.X = start.X
+	}
+	if start.Y < min.Y {
+		min.Y = start.Y
+	}
+	if start.X > max.X {
+		max.X = start.X
+	}
+	if start.Y > max.Y {
+		max.Y = start.Y
+	}
+
+	match := w.at(start)
+	seen := map[Coord]bool{start: true}
+	region := []Coord{start}
+	for i := 0; i < len(region); i++ {
+		for _, n := range [...]Coord{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			c := region[i].Add(n)
+			if c.X < min.X || c.Y < min.Y || c.X > max.X || c.Y > max.Y || seen[c] {
+				continue
+			}
+			seen[c] = true
+			if w.at(c) == match {
+				region = append(region, c)
+			}
+		}
+	}
+	return region
+}
+
+// tileRect returns the corners of the rectangle with a and b at opposite
+// corners, smallest first.
+func tileRect(a, b Coord) (min, max Coord) {
+	min, max = a, b
+	if min.X > max.X {
+		min.X, max.X = max.X, min.X
+	}
+	if min.Y > max.Y {
+		min.Y, max.Y = max.Y, min.Y
+	}
+	return
+}
+
+// tileLine returns the tiles on a line from a to b, including both ends, so
+// a fast drag does not leave gaps.
+func tileLine(a, b Coord) []Coord {
+	d := b.Sub(a)
+	sx, sy := int64(1), int64(1)
+	if d.X < 0 {
+		d.X, sx = -d.X, -1
+	}
+	if d.Y < 0 {
+		d.Y, sy = -d.Y, -1
+	}
+
+	line := []Coord{a}
+	err := d.X - d.Y
+	for c := a; c != b; line = append(line, c) {
+		if e2 := 2 * err; e2 > -d.Y {
+			err -= d.Y
+			c.X += sx
+		} else {
+			err += d.X
+			c.Y += sy
+		}
+	}
+	return line
+}
+
+// renderTile draws t on its own, as the brush indicator in the editor.
+func renderTile(img draw.Image, r image.Rectangle, terrain []*image.RGBA, t WorldTile) {
+	if t.Tile < 0 || t.Tile >= len(terrain) {
+		return
+	}
+	tr, tm := terrain[t.Tile], tilemask[0]
+	if t.Solid {
+		tm = tilemask[1<<0]
+		if t.Shape != TileShape_Full {
+			tm = shapedTilemask(1<<0, t.Shape)
+		}
+	}
+	draw.DrawMask(img, r, tr, tr.Rect.Min, tm, tm.Rect.Min, draw.Over)
+	switch t.SpecialTile {
+	case SpecialTile_Fragile:
+		if t.Solid {
+			draw.DrawMask(img, r, image.Black, image.ZP, fragilemask, image.ZP, draw.Over)
+		}
+	case SpecialTile_Liquid:
+		if !t.Solid {
+			draw.Draw(img, r, liquidfill, image.ZP, draw.Over)
+		}
+	case SpecialTile_Switch:
+		if t.Solid {
+			draw.Draw(img, image.Rect(r.Min.X+TileSize/4, r.Min.Y, r.Max.X-TileSize/4, r.Min.Y+2), switchfill, image.ZP, draw.Src)
+		}
+	}
+}
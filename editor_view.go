@@ -0,0 +1,116 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+const (
+	EditorZoomMin = -3 // the editor can zoom out to 1/8 of the size...
+	EditorZoomMax = 1  // ...and in to twice the size.
+
+	EditorGridSpacing = 8 // closest together lines in the tile grid can be, in pixels
+
+	EditorMinimapWidth  = 160 // biggest the minimap can be, in pixels
+	EditorMinimapHeight = 80
+	EditorMinimapScale  = 4 // most pixels across a tile can be on the minimap
+	EditorMinimapMargin = 4 // pixels between the minimap and the edge of the window
+)
+
+var (
+	gridfill        = image.NewUniform(color.RGBA{0, 0, 0, 48})
+	minimapSolid    = color.RGBA{64, 64, 64, 255}
+	minimapLiquid   = color.RGBA{64, 128, 224, 255}
+	minimapViewport = image.NewUniform(color.RGBA{192, 0, 0, 255})
+)
+
+// renderGrid draws lines between tiles that are size pixels across, with the
+// tile at 0,0 having its top left corner at offX, offY. Zoomed far enough out,
+// only every second, fourth, and so on line is drawn.
+func renderGrid(img *image.RGBA, offX, offY, size int64) {
+	step := size
+	for step < EditorGridSpacing {
+		step *= 2
+	}
+	b := img.Rect
+	x, y := offX%step, offY%step
+	if x < 0 {
+		x += step
+	}
+	if y < 0 {
+		y += step
+	}
+	for ; x < int64(b.Dx()); x += step {
+		draw.Draw(img, image.Rect(b.Min.X+int(x), b.Min.Y, b.Min.X+int(x)+1, b.Max.Y), gridfill, image.ZP, draw.Over)
+	}
+	for ; y < int64(b.Dy()); y += step {
+		draw.Draw(img, image.Rect(b.Min.X, b.Min.Y+int(y), b.Max.X, b.Min.Y+int(y)+1), gridfill, image.ZP, draw.Over)
+	}
+}
+
+// minimap is where the whole of a level is drawn small in the corner of the
+// editor's window.
+type minimap struct {
+	Rect  image.Rectangle
+	Scale float64 // pixels across each tile
+	Min   Coord   // the tile in the top left corner
+}
+
+// newMinimap returns the minimap of w for a window the size of screen.
+func newMinimap(screen image.Rectangle, w *World) minimap {
+	tw, th := float64(w.Max.X-w.Min.X+1), float64(w.Max.Y-w.Min.Y+1)
+	scale := math.Min(math.Min(EditorMinimapWidth/tw, EditorMinimapHeight/th), EditorMinimapScale)
+	dx, dy := int(math.Ceil(tw*scale)), int(math.Ceil(th*scale))
+	max := screen.Max.Sub(image.Pt(EditorMinimapMargin, EditorMinimapMargin))
+	return minimap{
+		Rect:  image.Rectangle{max.Sub(image.Pt(dx, dy)), max},
+		Scale: scale,
+		Min:   w.Min,
+	}
+}
+
+// tile returns the tile shown at p.
+func (m minimap) tile(p image.Point) Coord {
+	return Coord{
+		m.Min.X + int64(math.Floor(float64(p.X-m.Rect.Min.X)/m.Scale)),
+		m.Min.Y + int64(math.Floor(float64(p.Y-m.Rect.Min.Y)/m.Scale)),
+	}
+}
+
+// point returns where the point x, y, in pixels of the world, is shown.
+func (m minimap) point(x, y int64) image.Point {
+	return image.Pt(
+		m.Rect.Min.X+int(math.Floor((float64(x)/TileSize-float64(m.Min.X))*m.Scale)),
+		m.Rect.Min.Y+int(math.Floor((float64(y)/TileSize-float64(m.Min.Y))*m.Scale)),
+	)
+}
+
+// render draws w on the minimap, with view, in pixels of the world, outlined.
+func (m minimap) render(img *image.RGBA, w *World, view image.Rectangle) {
+	draw.Draw(img, m.Rect.Inset(-1), image.Black, image.ZP, draw.Src)
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		for x := m.Rect.Min.X; x < m.Rect.Max.X; x++ {
+			t := w.at(m.tile(image.Pt(x, y)))
+			switch {
+			case t.Solid:
+				img.SetRGBA(x, y, minimapSolid)
+			case t.SpecialTile == SpecialTile_Liquid:
+				img.SetRGBA(x, y, minimapLiquid)
+			default:
+				img.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+
+	r := image.Rectangle{m.point(int64(view.Min.X), int64(view.Min.Y)), m.point(int64(view.Max.X), int64(view.Max.Y))}
+	for _, edge := range [...]image.Rectangle{
+		{r.Min, image.Pt(r.Max.X, r.Min.Y+1)},
+		{image.Pt(r.Min.X, r.Max.Y-1), r.Max},
+		{r.Min, image.Pt(r.Min.X+1, r.Max.Y)},
+		{image.Pt(r.Max.X-1, r.Min.Y), r.Max},
+	} {
+		draw.Draw(img, edge.Intersect(m.Rect), minimapViewport, image.ZP, draw.Src)
+	}
+}
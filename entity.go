@@ -0,0 +1,256 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+type EntityKind int
+
+const (
+	Entity_Platform EntityKind = iota // goes around its path forever
+	Entity_Door                       // slides along its path once its switch is pressed
+	Entity_Crusher                    // slams from the first point to the second and slowly rises again
+	Entity_count
+)
+
+var entityKind_names [Entity_count]string = [...]string{
+	Entity_Platform: "platform",
+	Entity_Door:     "door",
+	Entity_Crusher:  "crusher",
+}
+
+const (
+	EntitySpeed  = TileSize * PixelSize * 2 / TicksPerSecond // default speed
+	CrusherSlam  = 4                                         // times faster a crusher falls than rises
+	CrusherPause = TicksPerSecond                            // ticks a crusher waits at the top
+)
+
+// Entity is a solid block that moves by itself. Where it is depends only on
+// the tick and, for doors, when the switch was pressed, so every client
+// agrees.
+type Entity struct {
+	Kind   EntityKind
+	Size   Coord   // in tiles
+	Path   []Coord // tile coordinates of the top left corner
+	Speed  int64   // per tick
+	Switch Coord   // tile coordinates of a door's switch tile
+	Tile   int
+}
+
+// length returns the distance along the path, back to the start if loop is
+// set.
+func (e *Entity) length(loop bool) int64 {
+	var total int64
+	for i := range e.Path {
+		if i+1 < len(e.Path) {
+			total += pathSegment(e.Path[i], e.Path[i+1])
+		} else if loop {
+			total += pathSegment(e.Path[i], e.Path[0])
+		}
+	}
+	return total
+}
+
+// along returns the position dist along the path.
+func (e *Entity) along(dist int64, loop bool) Coord {
+	const size = TileSize * PixelSize
+	for i := range e.Path {
+		next := i + 1
+		if next == len(e.Path) {
+			if !loop {
+				break
+			}
+			next = 0
+		}
+		l := pathSegment(e.Path[i], e.Path[next])
+		if dist < l {
+			a, b := e.Path[i], e.Path[next]
+			return Coord{
+				a.X*size + (b.X-a.X)*size*dist/l,
+				a.Y*size + (b.Y-a.Y)*size*dist/l,
+			}
+		}
+		dist -= l
+	}
+	last := e.Path[len(e.Path)-1]
+	return Coord{last.X * size, last.Y * size}
+}
+
+// stuck reports whether e has more than one point on its path but they are
+// all the same tile, so it has nowhere to go.
+func (e *Entity) stuck() bool {
+	return len(e.Path) > 1 && e.length(true) == 0
+}
+
+func pathSegment(a, b Coord) int64 {
+	d := b.Sub(a)
+	return int64(math.Sqrt(float64(d.LengthSquared()))) * TileSize * PixelSize
+}
+
+// EntityPosition returns the top left corner of entity i at tick.
+func (state *State) EntityPosition(i int, tick uint64) Coord {
+	e := &state.world.Entities[i]
+	if len(e.Path) == 0 {
+		return Coord{}
+	}
+	if len(e.Path) == 1 || e.Speed <= 0 {
+		return e.along(0, false)
+	}
+
+	switch e.Kind {
+	case Entity_Platform:
+		l := e.length(true)
+		if l == 0 {
+			return e.along(0, false)
+		}
+		return e.along(int64(tick)*e.Speed%l, true)
+
+	case Entity_Door:
+		opened, ok := state.Opened[i]
+		if !ok || tick < opened {
+			return e.along(0, false)
+		}
+		return e.along(int64(tick-opened)*e.Speed, false)
+
+	case Entity_Crusher:
+		l := pathSegment(e.Path[0], e.Path[1])
+		slam := uint64(l/(e.Speed*CrusherSlam)) + 1
+		rise := uint64(l/e.Speed) + 1
+		t := tick % (CrusherPause + slam + rise)
+		switch {
+		case t < CrusherPause:
+			return e.along(0, false)
+		case t < CrusherPause+slam:
+			return e.along(l*int64(t-CrusherPause)/int64(slam), false)
+		default:
+			return e.along(l-l*int64(t-CrusherPause-slam)/int64(rise), false)
+		}
+	}
+	return e.along(0, false)
+}
+
+// EntityBox returns the corners of entity i at tick.
+func (state *State) EntityBox(i int, tick uint64) (min, max Coord) {
+	min = state.EntityPosition(i, tick)
+	max = min.Add(Coord{state.world.Entities[i].Size.X * TileSize * PixelSize, state.world.Entities[i].Size.Y * TileSize * PixelSize})
+	return
+}
+
+// PressSwitch opens every door wired to the switch tile at x, y and lets the
+// triggers know it was pressed.
+func (state *State) PressSwitch(x, y int64) {
+	state.pressed = append(state.pressed, Coord{x, y})
+	for i := range state.world.Entities {
+		e := &state.world.Entities[i]
+		if e.Kind == Entity_Door && e.Switch == (Coord{x, y}) {
+			state.OpenDoor(i)
+		}
+	}
+}
+
+// OpenDoor starts door entity i opening if it has not already.
+func (state *State) OpenDoor(i int) {
+	if i < 0 || i >= len(state.world.Entities) {
+		return
+	}
+	if _, ok := state.Opened[i]; ok {
+		return
+	}
+	if state.Opened == nil {
+		state.Opened = make(map[int]uint64)
+	}
+	state.Opened[i] = state.Tick
+}
+
+// MoveEntities carries the units riding each entity along with it and pushes
+// units out of its way. A unit that cannot get out of the way is crushed.
+func (state *State) MoveEntities() {
+	for i := range state.world.Entities {
+		oldMin, oldMax := state.EntityBox(i, state.Tick-1)
+		newMin, newMax := state.EntityBox(i, state.Tick)
+		delta := newMin.Sub(oldMin)
+		if delta.Zero() {
+			continue
+		}
+
+		areaMin, areaMax := oldMin.Sub(Coord{1, 1}), oldMax.Add(Coord{1, 1})
+		if delta.X < 0 {
+			areaMin.X += delta.X
+		} else {
+			areaMax.X += delta.X
+		}
+		if delta.Y < 0 {
+			areaMin.Y += delta.Y
+		} else {
+			areaMax.Y += delta.Y
+		}
+
+		var moving []*Unit
+		state.EachUnitIn(areaMin, areaMax, func(u *Unit) {
+			if u.Health <= 0 {
+				return
+			}
+			min, max := u.Size(state, u).Hull()
+			min, max = min.Add(u.Position), max.Add(u.Position)
+			riding := max.Y == oldMin.Y && max.X > oldMin.X && min.X < oldMax.X
+			inside := max.X > newMin.X && min.X < newMax.X && max.Y > newMin.Y && min.Y < newMax.Y
+			if riding || inside {
+				moving = append(moving, u)
+			}
+		})
+
+		state.pushing = i + 1
+		for _, u := range moving {
+			tr := state.Trace(u.Position, u.Position.Add(delta), u.Size(state, u), false)
+			tr.CollideWith(state, u)
+			u.Position = tr.End
+			state.UnitMoved(u)
+
+			min, max := u.Size(state, u).Hull()
+			min, max = min.Add(u.Position), max.Add(u.Position)
+			if max.X > newMin.X && min.X < newMax.X && max.Y > newMin.Y && min.Y < newMax.Y {
+				u.Kill(state, nil, DamageCrush)
+			}
+		}
+		state.pushing = 0
+	}
+}
+
+// renderEntities draws every entity in the world at its position in state.
+func renderEntities(img *image.RGBA, state *State, offX, offY int64) {
+	for i := range state.world.Entities {
+		min, _ := state.EntityBox(i, state.Tick)
+		renderEntity(img, state.world, &state.world.Entities[i], min.X/PixelSize+offX, min.Y/PixelSize+offY)
+	}
+}
+
+// renderEntity draws e with its top left corner at x, y in pixels. Its tiles
+// are edged as if it was a piece of w by itself.
+func renderEntity(img *image.RGBA, w *World, e *Entity, x, y int64) {
+	terrain := w.Terrain()
+	tile := e.Tile
+	if tile < 0 || tile >= len(terrain) {
+		tile = 0
+	}
+	tr := terrain[tile]
+	solid := func(tx, ty int64) bool {
+		return tx >= 0 && ty >= 0 && tx < e.Size.X && ty < e.Size.Y
+	}
+	for tx := int64(0); tx < e.Size.X; tx++ {
+		for ty := int64(0); ty < e.Size.Y; ty++ {
+			m := 1 << 0
+			for bit, n := range [...]Coord{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}} {
+				if solid(tx+n.X, ty+n.Y) {
+					m |= 1 << uint(bit+1)
+				}
+			}
+			tm := tilemask[m]
+			r := image.Rect(int(x+tx*TileSize), int(y+ty*TileSize), int(x+tx*TileSize+TileSize), int(y+ty*TileSize+TileSize))
+			if r.Overlaps(img.Rect) {
+				draw.DrawMask(img, r, tr, tr.Rect.Min, tm, tm.Rect.Min, draw.Over)
+			}
+		}
+	}
+}
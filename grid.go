@@ -0,0 +1,136 @@
+package main
+
+// GridCellSize is the width and height of a cell in the unit grid.
+const GridCellSize = 4 * TileSize * PixelSize
+
+type gridEntry struct {
+	*Unit
+	Min, Max Coord // range of cells the unit is in
+	seen     uint64
+}
+
+// unitGrid is a spatial hash of the units in a State. It is rebuilt at the
+// start of every tick and kept up to date by State.UnitMoved.
+type unitGrid struct {
+	cells   map[Coord][]*gridEntry
+	entries map[*Unit]*gridEntry
+	query   uint64
+}
+
+func newUnitGrid(state *State) *unitGrid {
+	g := &unitGrid{
+		cells:   make(map[Coord][]*gridEntry),
+		entries: make(map[*Unit]*gridEntry),
+	}
+	for i := range state.Mans {
+		g.update(state, &state.Mans[i])
+	}
+	for _, u := range state.Units {
+		g.update(state, u)
+	}
+	return g
+}
+
+func gridCells(min, max Coord) (Coord, Coord) {
+	min = min.Floor(GridCellSize)
+	max = max.Floor(GridCellSize)
+	return Coord{min.X / GridCellSize, min.Y / GridCellSize}, Coord{max.X / GridCellSize, max.Y / GridCellSize}
+}
+
+func (g *unitGrid) update(state *State, u *Unit) {
+	min, max := u.Size(state, u).Hull()
+	min, max = gridCells(min.Add(u.Position), max.Add(u.Position))
+
+	e, ok := g.entries[u]
+	if ok {
+		if e.Min == min && e.Max == max {
+			return
+		}
+		g.remove(e)
+	} else {
+		e = &gridEntry{Unit: u}
+		g.entries[u] = e
+	}
+
+	e.Min, e.Max = min, max
+	for x := min.X; x <= max.X; x++ {
+		for y := min.Y; y <= max.Y; y++ {
+			g.cells[Coord{x, y}] = append(g.cells[Coord{x, y}], e)
+		}
+	}
+}
+
+// forget removes u from the grid.
+func (g *unitGrid) forget(u *Unit) {
+	if e, ok := g.entries[u]; ok {
+		g.remove(e)
+		delete(g.entries, u)
+	}
+}
+
+func (g *unitGrid) remove(e *gridEntry) {
+	for x := e.Min.X; x <= e.Max.X; x++ {
+		for y := e.Min.Y; y <= e.Max.Y; y++ {
+			cell := g.cells[Coord{x, y}]
+			for i := range cell {
+				if cell[i] == e {
+					cell = append(cell[:i], cell[i+1:]...)
+					break
+				}
+			}
+			if len(cell) == 0 {
+				delete(g.cells, Coord{x, y})
+			} else {
+				g.cells[Coord{x, y}] = cell
+			}
+		}
+	}
+}
+
+func (g *unitGrid) each(min, max Coord, f func(*Unit)) {
+	g.query++
+	min, max = gridCells(min, max)
+	for x := min.X; x <= max.X; x++ {
+		for y := min.Y; y <= max.Y; y++ {
+			for _, e := range g.cells[Coord{x, y}] {
+				if e.seen != g.query {
+					e.seen = g.query
+					f(e.Unit)
+				}
+			}
+		}
+	}
+}
+
+// UnitMoved must be called after a unit is moved or added during a tick so
+// traces can find it in its new position.
+func (state *State) UnitMoved(u *Unit) {
+	if state.grid != nil {
+		state.grid.update(state, u)
+	}
+}
+
+// UnitRemoved must be called after a unit is taken out of State.Units during
+// a tick so traces stop finding it.
+func (state *State) UnitRemoved(u *Unit) {
+	if state.grid != nil {
+		state.grid.forget(u)
+	}
+}
+
+// EachUnitIn calls f for every unit that EachUnit would that may overlap the
+// area between min and max.
+func (state *State) EachUnitIn(min, max Coord, f func(*Unit)) {
+	if state.grid == nil {
+		state.EachUnit(f)
+		return
+	}
+
+	held := state.heldUnits()
+
+	state.grid.each(min, max, func(u *Unit) {
+		if !isHeld(held, u) {
+			f(u)
+		}
+	})
+}
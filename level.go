@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"github.com/Rnoadm/wdvn/res"
+	"io"
+	"io/ioutil"
+)
+
+// LevelVersion is the version of the text level format written by
+// EncodeLevel. DecodeLevel reads this version and any before it.
+const LevelVersion = 2
+
+// DefaultTileset and DefaultParallax are used by levels that do not choose
+// their own.
+const DefaultTileset = "terrain"
+
+var DefaultParallax = []string{"parallax_0", "parallax_1"}
+
+// tilesetsrc and parallaxsrc are the images a level can choose by name.
+var (
+	tilesetsrc = map[string]string{
+		"terrain": res.TerrainPng,
+	}
+	parallaxsrc = map[string]string{
+		"parallax_0": res.Parallax0Png,
+		"parallax_1": res.Parallax1Png,
+	}
+)
+
+// LevelMeta describes a level as a whole.
+type LevelMeta struct {
+	Title     string
+	Author    string
+	Music     string   // name of the level's music, kept for when the game has sound
+	Spawn     Coord    // where mans start, like State.SpawnPoint
+	Tileset   string   // name of the terrain tiles, or "" for DefaultTileset
+	Parallax  []string // background layers back to front, or none for DefaultParallax
+	TimeLimit uint64   // ticks the mans have to finish the level, or 0 for no limit
+	Lives     int64    // lives each man starts with, or 0 for ManLives
+}
+
+func (m *LevelMeta) check() error {
+	if _, ok := tilesetsrc[m.Tileset]; !ok && m.Tileset != "" {
+		return fmt.Errorf("level: unknown tileset %q", m.Tileset)
+	}
+	for _, name := range m.Parallax {
+		if _, ok := parallaxsrc[name]; !ok {
+			return fmt.Errorf("level: unknown parallax layer %q", name)
+		}
+	}
+	if m.Lives < 0 {
+		return fmt.Errorf("level: lives cannot be negative")
+	}
+	return nil
+}
+
+// check returns an error if t cannot be put in a level whose tileset has
+// tiles tiles. Liquid is the only special a tile can have without being
+// solid, and the only one a solid tile cannot have.
+func (t WorldTile) check(tiles int) error {
+	if t.Tile < 0 || t.Tile >= tiles {
+		return fmt.Errorf("tile index %d is not in the tileset, which has %d tiles", t.Tile, tiles)
+	}
+	if t.Shape < 0 || t.Shape >= TileShape_count {
+		return fmt.Errorf("unknown tile shape %d", t.Shape)
+	}
+	if t.SpecialTile < 0 || t.SpecialTile >= SpecialTile_count {
+		return fmt.Errorf("unknown special tile %d", t.SpecialTile)
+	}
+	if t.Solid && t.SpecialTile == SpecialTile_Liquid {
+		return fmt.Errorf("liquid tiles cannot be solid")
+	}
+	if !t.Solid && t.SpecialTile != SpecialTile_None && t.SpecialTile != SpecialTile_Liquid {
+		return fmt.Errorf("%s tiles must be solid", specialTile_names[t.SpecialTile])
+	}
+	return nil
+}
+
+// levelLegend holds the characters used for tiles in a text level, in the
+// order they are handed out. The empty tile is always '.'.
+const levelLegend = "#ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!$%&*+-/:;<=>?@^_|~"
+
+// level is the text form of a World. Each row is a string of legend
+// characters from Min.X to Max.X, and the rows go from Min.Y to Max.Y.
+type level struct {
+	Version   int
+	Title     string `json:",omitempty"`
+	Author    string `json:",omitempty"`
+	Music     string `json:",omitempty"`
+	Spawn     Coord
+	Tileset   string   `json:",omitempty"`
+	Parallax  []string `json:",omitempty"`
+	TimeLimit uint64   `json:",omitempty"`
+	Lives     int64    `json:",omitempty"`
+	Min, Max  Coord
+	Legend    map[string]levelTile
+	Rows      []string
+	Entities  []levelEntity  `json:",omitempty"`
+	Triggers  []levelTrigger `json:",omitempty"`
+}
+
+type levelTile struct {
+	Tile    int    `json:",omitempty"`
+	Solid   bool   `json:",omitempty"`
+	Special string `json:",omitempty"`
+	Shape   string `json:",omitempty"`
+}
+
+type levelEntity struct {
+	Kind   string
+	Size   Coord
+	Path   []Coord
+	Speed  int64
+	Switch *Coord `json:",omitempty"`
+	Tile   int    `json:",omitempty"`
+}
+
+type levelTrigger struct {
+	Min, Max Coord
+	Event    string
+	Repeat   uint64 `json:",omitempty"`
+	Actions  []levelAction
+}
+
+type levelAction struct {
+	Kind string
+	Door int `json:",omitempty"`
+	At   Coord
+	Text string `json:",omitempty"`
+}
+
+// lookupName returns the index of name in names.
+func lookupName(what string, names []string, name string) (int, error) {
+	if name == "" {
+		return 0, nil
+	}
+	for i, n := range names {
+		if n == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("level: unknown %s %q", what, name)
+}
+
+type LevelFormat int
+
+const (
+	LevelFormat_Gob   LevelFormat = iota // the old format, World as it is in memory
+	LevelFormat_Text                     // the versioned format written by EncodeLevel
+	LevelFormat_Tiled                    // a Tiled JSON map, see tiled.go
+	LevelFormat_count
+)
+
+var levelFormat_names [LevelFormat_count]string = [...]string{
+	LevelFormat_Gob:   "gob",
+	LevelFormat_Text:  "text",
+	LevelFormat_Tiled: "tiled",
+}
+
+func (f LevelFormat) String() string {
+	return levelFormat_names[f]
+}
+
+// DetectLevelFormat returns the format the level in b is written in.
+func DetectLevelFormat(b []byte) LevelFormat {
+	if IsTiledLevel(b) {
+		return LevelFormat_Tiled
+	}
+	if IsTextLevel(b) {
+		return LevelFormat_Text
+	}
+	return LevelFormat_Gob
+}
+
+// DecodeWorld reads a level in any of the formats.
+func DecodeWorld(r io.Reader) (*World, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var w *World
+	switch DetectLevelFormat(b) {
+	case LevelFormat_Text:
+		w, err = DecodeLevel(bytes.NewReader(b))
+	case LevelFormat_Tiled:
+		w, err = DecodeTiled(bytes.NewReader(b))
+	default:
+		w = new(World)
+		err = gob.NewDecoder(bytes.NewReader(b)).Decode(w)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Meta.check()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// IsTextLevel reports whether b looks like JSON, which is either the text
+// format or a Tiled map, rather than gob.
+func IsTextLevel(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) != 0 && b[0] == '{'
+}
+
+// DecodeLevel reads a level in the text format.
+func DecodeLevel(r io.Reader) (*World, error) {
+	var l level
+	err := json.NewDecoder(r).Decode(&l)
+	if err != nil {
+		return nil, err
+	}
+	if l.Version > LevelVersion {
+		return nil, fmt.Errorf("level: version %d is newer than this game (%d)", l.Version, LevelVersion)
+	}
+	if l.Version < 1 {
+		return nil, fmt.Errorf("level: missing version")
+	}
+	if l.Max.X < l.Min.X || l.Max.Y < l.Min.Y {
+		return nil, fmt.Errorf("level: Max must not be less than Min")
+	}
+	if int64(len(l.Rows)) != l.Max.Y-l.Min.Y+1 {
+		return nil, fmt.Errorf("level: %d rows for height %d", len(l.Rows), l.Max.Y-l.Min.Y+1)
+	}
+
+	w := &World{Min: l.Min, Max: l.Max}
+	w.Meta = LevelMeta{
+		Title:     l.Title,
+		Author:    l.Author,
+		Music:     l.Music,
+		Spawn:     l.Spawn,
+		Tileset:   l.Tileset,
+		Parallax:  l.Parallax,
+		TimeLimit: l.TimeLimit,
+		Lives:     l.Lives,
+	}
+	if err = w.Meta.check(); err != nil {
+		return nil, err
+	}
+	tiles, err := tilesetSize(w.Meta.Tileset)
+	if err != nil {
+		return nil, fmt.Errorf("level: tileset %q cannot be read: %v", w.Meta.Tileset, err)
+	}
+
+	legend := map[rune]WorldTile{'.': {}}
+	for key, t := range l.Legend {
+		if len([]rune(key)) != 1 || key == "." {
+			return nil, fmt.Errorf("level: legend key %q must be a single character other than '.'", key)
+		}
+		special, err := lookupName("special tile", specialTile_names[:], t.Special)
+		if err != nil {
+			return nil, err
+		}
+		shape, err := lookupName("tile shape", tileShape_names[:], t.Shape)
+		if err != nil {
+			return nil, err
+		}
+		tile := WorldTile{
+			Tile:        t.Tile,
+			Solid:       t.Solid,
+			SpecialTile: SpecialTile(special),
+			Shape:       TileShape(shape),
+		}
+		if err = tile.check(tiles); err != nil {
+			return nil, fmt.Errorf("level: legend %q: %v", key, err)
+		}
+		legend[[]rune(key)[0]] = tile
+	}
+
+	w.Tiles = make([]WorldTile, (w.Max.X-w.Min.X+1)*(w.Max.Y-w.Min.Y+1))
+	for dy, row := range l.Rows {
+		y := w.Min.Y + int64(dy)
+		chars := []rune(row)
+		if int64(len(chars)) != w.Max.X-w.Min.X+1 {
+			return nil, fmt.Errorf("level: row %d is %d tiles wide instead of %d", y, len(chars), w.Max.X-w.Min.X+1)
+		}
+		for dx, c := range chars {
+			t, ok := legend[c]
+			if !ok {
+				return nil, fmt.Errorf("level: row %d has %q, which is not in the legend", y, c)
+			}
+			i, _ := w.index(w.Min.X+int64(dx), y)
+			w.Tiles[i] = t
+		}
+	}
+
+	for i, e := range l.Entities {
+		kind, err := lookupName("entity kind", entityKind_names[:], e.Kind)
+		if err != nil {
+			return nil, err
+		}
+		entity := Entity{
+			Kind:  EntityKind(kind),
+			Size:  e.Size,
+			Path:  e.Path,
+			Speed: e.Speed,
+			Tile:  e.Tile,
+		}
+		if e.Switch != nil {
+			entity.Switch = *e.Switch
+		}
+		if entity.stuck() {
+			return nil, fmt.Errorf("level: entity %d has a path that does not go anywhere", i)
+		}
+		w.Entities = append(w.Entities, entity)
+	}
+
+	for _, t := range l.Triggers {
+		event, err := lookupName("trigger event", triggerEvent_names[:], t.Event)
+		if err != nil {
+			return nil, err
+		}
+		trigger := Trigger{
+			Min:    t.Min,
+			Max:    t.Max,
+			Event:  TriggerEvent(event),
+			Repeat: t.Repeat,
+		}
+		for _, a := range t.Actions {
+			kind, err := lookupName("action", actionKind_names[:], a.Kind)
+			if err != nil {
+				return nil, err
+			}
+			trigger.Actions = append(trigger.Actions, Action{
+				Kind: ActionKind(kind),
+				Door: a.Door,
+				At:   a.At,
+				Text: a.Text,
+			})
+		}
+		w.Triggers = append(w.Triggers, trigger)
+	}
+
+	return w, nil
+}
+
+// EncodeLevel writes w in the text format.
+func EncodeLevel(out io.Writer, w *World) error {
+	l := level{
+		Version:   LevelVersion,
+		Title:     w.Meta.Title,
+		Author:    w.Meta.Author,
+		Music:     w.Meta.Music,
+		Spawn:     w.Meta.Spawn,
+		Tileset:   w.Meta.Tileset,
+		Parallax:  w.Meta.Parallax,
+		TimeLimit: w.Meta.TimeLimit,
+		Lives:     w.Meta.Lives,
+		Min:       w.Min,
+		Max:       w.Max,
+		Legend:    make(map[string]levelTile),
+	}
+
+	chars := map[WorldTile]rune{{}: '.'}
+	next := []rune(levelLegend)
+	for y := w.Min.Y; y <= w.Max.Y; y++ {
+		var row []rune
+		for x := w.Min.X; x <= w.Max.X; x++ {
+			i, _ := w.index(x, y)
+			t := w.Tiles[i]
+			c, ok := chars[t]
+			if !ok {
+				if len(next) == 0 {
+					return fmt.Errorf("level: more than %d different tiles", len([]rune(levelLegend)))
+				}
+				c, next = next[0], next[1:]
+				chars[t] = c
+
+				lt := levelTile{Tile: t.Tile, Solid: t.Solid}
+				if t.SpecialTile != SpecialTile_None {
+					lt.Special = specialTile_names[t.SpecialTile]
+				}
+				if t.Shape != TileShape_Full {
+					lt.Shape = tileShape_names[t.Shape]
+				}
+				l.Legend[string(c)] = lt
+			}
+			row = append(row, c)
+		}
+		l.Rows = append(l.Rows, string(row))
+	}
+
+	for _, e := range w.Entities {
+		entity := levelEntity{
+			Kind:  entityKind_names[e.Kind],
+			Size:  e.Size,
+			Path:  e.Path,
+			Speed: e.Speed,
+			Tile:  e.Tile,
+		}
+		if e.Kind == Entity_Door {
+			sw := e.Switch
+			entity.Switch = &sw
+		}
+		l.Entities = append(l.Entities, entity)
+	}
+
+	for _, t := range w.Triggers {
+		trigger := levelTrigger{
+			Min:    t.Min,
+			Max:    t.Max,
+			Event:  triggerEvent_names[t.Event],
+			Repeat: t.Repeat,
+		}
+		for _, a := range t.Actions {
+			trigger.Actions = append(trigger.Actions, levelAction{
+				Kind: actionKind_names[a.Kind],
+				Door: a.Door,
+				At:   a.At,
+				Text: a.Text,
+			})
+		}
+		l.Triggers = append(l.Triggers, trigger)
+	}
+
+	b, err := json.MarshalIndent(&l, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(append(b, '\n'))
+	return err
+}
+
+// EncodeWorld writes w in the given format.
+func EncodeWorld(out io.Writer, w *World, format LevelFormat) error {
+	switch format {
+	case LevelFormat_Text:
+		return EncodeLevel(out, w)
+	case LevelFormat_Tiled:
+		return EncodeTiled(out, w)
+	}
+	return gob.NewEncoder(out).Encode(w)
+}
+
+// ConvertLevel reads a level in any format and writes it in the format named
+// to. If to is empty, text levels become gob and anything else becomes text.
+func ConvertLevel(out io.Writer, in io.Reader, to string) error {
+	b, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	w, err := DecodeWorld(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	format := LevelFormat_Text
+	if to != "" {
+		i, err := lookupName("level format", levelFormat_names[:], to)
+		if err != nil {
+			return err
+		}
+		format = LevelFormat(i)
+	} else if DetectLevelFormat(b) == LevelFormat_Text {
+		format = LevelFormat_Gob
+	}
+	return EncodeWorld(out, w, format)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// roundTripLevel returns a copy of FooLevel with some of everything a level
+// can have.
+func roundTripLevel() *World {
+	w := &World{
+		Min:   FooLevel.Min,
+		Max:   FooLevel.Max,
+		Tiles: append([]WorldTile(nil), FooLevel.Tiles...),
+	}
+	for i, t := range []WorldTile{
+		{Tile: 1, Solid: true, Shape: TileShape_Slope45Up},
+		{Tile: 2, Solid: true, Shape: TileShape_Half},
+		{Tile: 3, SpecialTile: SpecialTile_Liquid},
+		{Tile: 0, Solid: true, SpecialTile: SpecialTile_Fragile},
+		{Tile: 1, Solid: true, SpecialTile: SpecialTile_Switch, Shape: TileShape_Half},
+		{Tile: 2, Solid: true, SpecialTile: SpecialTile_Checkpoint},
+		{Tile: 0, Solid: true, SpecialTile: SpecialTile_Bounce},
+	} {
+		j, _ := w.index(w.Min.X+2+int64(i), w.Min.Y+2)
+		w.Tiles[j] = t
+	}
+	w.Meta = LevelMeta{
+		Title:     "Round Trip",
+		Author:    "wdvn",
+		Music:     "theme",
+		Spawn:     Coord{3*TileSize*PixelSize + 5, 7*TileSize*PixelSize + 1},
+		Tileset:   "terrain",
+		Parallax:  []string{"parallax_1", "parallax_0"},
+		TimeLimit: 90 * TicksPerSecond,
+		Lives:     3,
+	}
+	w.Entities = []Entity{
+		{Kind: Entity_Platform, Size: Coord{3, 1}, Path: []Coord{{2, 2}, {6, 2}, {6, 4}}, Speed: EntitySpeed},
+		{Kind: Entity_Door, Size: Coord{1, 3}, Path: []Coord{{8, 1}, {8, -2}}, Speed: EntitySpeed / 2, Switch: Coord{10, 5}, Tile: 1},
+		{Kind: Entity_Crusher, Size: Coord{3, 2}, Path: []Coord{{12, 0}, {12, 4}}, Speed: EntitySpeed},
+	}
+	w.Triggers = []Trigger{
+		{Min: Coord{1, 1}, Max: Coord{4, 3}, Event: Trigger_Enter, Actions: []Action{
+			{Kind: Action_OpenDoor, Door: 1, At: Coord{8, 1}},
+			{Kind: Action_Floater, At: Coord{2, 0}, Text: "hello"},
+		}},
+		{Min: Coord{5, 5}, Max: Coord{5, 5}, Event: Trigger_Killed, Repeat: 2 * TicksPerSecond, Actions: []Action{
+			{Kind: Action_SpawnUnit, At: Coord{6, 3}},
+			{Kind: Action_Checkpoint, At: Coord{5, 4}},
+		}},
+	}
+	return w
+}
+
+func TestLevelRoundTrip(t *testing.T) {
+	for _, format := range []LevelFormat{LevelFormat_Text, LevelFormat_Tiled} {
+		w := roundTripLevel()
+
+		var buf bytes.Buffer
+		if err := EncodeWorld(&buf, w, format); err != nil {
+			t.Fatalf("format %d: encoding: %v", format, err)
+		}
+		if f := DetectLevelFormat(buf.Bytes()); f != format {
+			t.Errorf("format %d: detected as format %d", format, f)
+		}
+
+		var got *World
+		var err error
+		if format == LevelFormat_Tiled {
+			got, err = DecodeTiled(&buf)
+		} else {
+			got, err = DecodeLevel(&buf)
+		}
+		if err != nil {
+			t.Fatalf("format %d: decoding: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("format %d: level changed:\nbefore: %+v\nafter:  %+v", format, w, got)
+		}
+	}
+}
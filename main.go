@@ -1,13 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/binary"
-	"encoding/gob"
 	"flag"
+	"fmt"
 	"github.com/skelterjohn/go.wde"
 	_ "github.com/skelterjohn/go.wde/init"
 	"io"
+	"io/ioutil"
 	"log"
 	"math/rand"
 	"net"
@@ -26,16 +28,26 @@ func init() {
 
 var (
 	flagHost    = flag.String("host", "", "Start a dedicated server on this address. Example: \":7777\"")
+	flagMaxMans = flag.Int("maxmans", 16, "most mans a server will have before players have to share")
 	flagAddress = flag.String("addr", "", "address to connect to, like \""+net.JoinHostPort(externalIP(), "7777")+"\"")
-	flagEditor  = flag.String("edit", "", "filename of level to edit")
+	flagEditor  = flag.String("edit", "", "filename of level to edit. with -host, host an edit session for it and save to it; with -addr, join the edit session there and save to it")
+	flagConvert = flag.String("convert", "", "convert a level file between the old gob format and the text format, writing the result to stdout")
+	flagTo      = flag.String("to", "", "format for -convert to write: \"text\", \"gob\" or \"tiled\" (a Tiled JSON map)")
+
+	flagTuning     = flag.String("tuning", "", "JSON file of man stats and weapon values to use instead of the defaults")
+	flagDumpTuning = flag.Bool("dumptuning", false, "print the tuning values as JSON and exit")
+	flagRules      = flag.String("rules", "", "JSON file of server rules (FriendlyFire, FallDamage, CollisionDamage, ManKills, RoundTime, ...)")
+	flagVersus     = flag.Bool("versus", false, "mans play against each other for points instead of together")
 
 	flagLevel       = flag.String("level", "", "filename of level to play")
+	flagValidate    = flag.String("validate", "", "check a level file for problems, print them and exit with status 1 if there are any")
 	flagWidth       = flag.Int("w", 800, "width")
 	flagHeight      = flag.Int("h", 300, "height")
 	flagSplitScreen = flag.Bool("ss", false, "split screen")
 
 	flagRecord     = flag.String("record", "", "record a replay to this file")
 	flagRender     = flag.String("render", "", "play a replay from this file as YUV4MPEG2 on stdout")
+	flagStats      = flag.String("stats", "", "print the stats at the end of a replay from this file as JSON on stdout")
 	flagProfile    = flag.String("prof", "", "start a pprof server for developer use")
 	flagCPUProfile = flag.Bool("cpuprofile", false, "profile to a file instead of starting a server")
 )
@@ -49,7 +61,7 @@ var (
 func main() {
 	flag.Parse()
 
-	if *flagHost != "" && *flagAddress != "" || *flagAddress != "" && *flagEditor != "" || *flagEditor != "" && *flagHost != "" {
+	if *flagHost != "" && *flagAddress != "" {
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -71,6 +83,46 @@ func main() {
 		}
 	}
 
+	if *flagTuning != "" {
+		f, err := os.Open(*flagTuning)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = LoadTuning(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := CheckTuning(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *flagRules != "" {
+		f, err := os.Open(*flagRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = LoadRules(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *flagVersus {
+		Rules.Versus = true
+	}
+
+	if *flagDumpTuning {
+		err := DumpTuning(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if *flagRender != "" {
 		f, err := os.Open(*flagRender)
 		if err != nil {
@@ -92,6 +144,65 @@ func main() {
 		return
 	}
 
+	if *flagConvert != "" {
+		f, err := os.Open(*flagConvert)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		err = ConvertLevel(os.Stdout, f, *flagTo)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if *flagValidate != "" {
+		f, err := os.Open(*flagValidate)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w, err := DecodeWorld(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		problems := ValidateLevel(w)
+		for _, p := range problems {
+			fmt.Printf("%s: %v\n", *flagValidate, p)
+		}
+		if len(problems) != 0 {
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if *flagStats != "" {
+		f, err := os.Open(*flagStats)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer r.Close()
+
+		err = ReplayStats(os.Stdout, r)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
 	signalch := make(chan os.Signal)
 	signal.Notify(signalch, os.Interrupt)
 	go func() {
@@ -102,7 +213,6 @@ func main() {
 
 	level := FooLevel
 	if *flagLevel != "" {
-		level = &World{}
 		func() {
 			f, err := os.Open(*flagLevel)
 			if err != nil {
@@ -110,13 +220,37 @@ func main() {
 			}
 			defer f.Close()
 
-			err = gob.NewDecoder(f).Decode(level)
+			level, err = DecodeWorld(f)
 			if err != nil {
 				panic(err)
 			}
 		}()
 	}
 
+	if *flagHost != "" && *flagEditor != "" {
+		b, err := ioutil.ReadFile(*flagEditor)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// the level is saved in the format it was loaded in.
+		format := DetectLevelFormat(b)
+		level, err := DecodeWorld(bytes.NewReader(b))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		l, err := net.Listen("tcp", *flagHost)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		quitWait.Add(1)
+		go ListenEdit(l, level, *flagEditor, format)
+		quitWait.Wait()
+		return
+	}
+
 	if *flagHost != "" {
 		l, err := net.Listen("tcp", *flagHost)
 		if err != nil {
@@ -129,6 +263,14 @@ func main() {
 		return
 	}
 
+	if *flagEditor != "" {
+		quitWait.Add(1)
+		go Editor(*flagEditor, *flagAddress)
+		wde.Run()
+		quitWait.Wait()
+		return
+	}
+
 	if *flagRecord != "" {
 		f, err := os.Create(*flagRecord)
 		if err != nil {
@@ -150,7 +292,7 @@ func main() {
 
 			var l [binary.MaxVarintLen64]byte
 
-			i := binary.PutUvarint(l[:], 1)
+			i := binary.PutUvarint(l[:], 2)
 
 			n, err := w.Write(l[:i])
 			if err == nil && n != i {
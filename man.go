@@ -86,6 +86,21 @@ var ManData [res.Man_count]manData = [...]manData{
 		GravityCrouch:      2 * Gravity,
 		Color:              color.RGBA{0, 0, 192, 255},
 	},
+	res.Man_Portal: {
+		MoveSpeed:          2 * PixelSize,
+		MoveSpeedCrouch:    1 * PixelSize,
+		MoveSpeedAir:       2 * PixelSize,
+		MoveSpeedAirCrouch: 1 * PixelSize,
+		JumpSpeed:          300 * PixelSize,
+		Size:               Coord{30 * PixelSize, 46 * PixelSize},
+		SizeCrouch:         Coord{30 * PixelSize, 30 * PixelSize},
+		MaxHealth:          10000,
+		Mass:               1000,
+		MassCrouch:         2000,
+		Gravity:            Gravity,
+		GravityCrouch:      2 * Gravity,
+		Color:              color.RGBA{128, 32, 192, 255},
+	},
 }
 
 func Scale(delta Coord, distance float64) Coord {
@@ -113,6 +128,26 @@ type Man interface {
 	Checkpoint() *Coord
 	Crouching() bool
 	Ping() time.Duration
+	Base() *ManUnitData
+}
+
+// NewMan returns a man of the given class that starts with the lives,
+// checkpoint and input in data.
+func NewMan(man res.Man, data ManUnitData) Man {
+	data.Man_ = man
+	switch man {
+	case res.Man_Whip:
+		return &WhipMan{ManUnitData: data}
+	case res.Man_Density:
+		return &DensityMan{ManUnitData: data}
+	case res.Man_Vacuum:
+		return &VacuumMan{ManUnitData: data, Lemons_: Tuning.LemonAmmo}
+	case res.Man_Normal:
+		return &NormalMan{ManUnitData: data}
+	case res.Man_Portal:
+		return &PortalMan{ManUnitData: data}
+	}
+	panic("unknown man: " + man.String())
 }
 
 func init() {
@@ -121,6 +156,7 @@ func init() {
 	gob.Register((*VacuumMan)(nil))
 	gob.Register((*Lemon)(nil))
 	gob.Register((*NormalMan)(nil))
+	gob.Register((*PortalMan)(nil))
 }
 
 type ManUnitData struct {
@@ -132,6 +168,8 @@ type ManUnitData struct {
 	Lives_      int64
 	Checkpoint_ Coord
 	Ping_       time.Duration
+	HurtBy_     UnitRef // man who last hurt this one
+	HurtAt_     uint64
 }
 
 func (m *ManUnitData) UpdateDead(state *State, u *Unit) {
@@ -140,9 +178,10 @@ func (m *ManUnitData) UpdateDead(state *State, u *Unit) {
 		m.Respawn_ = state.Tick + RespawnTime
 	}
 	if m.Respawn_ <= state.Tick {
-		if m.Lives_ > 0 {
+		if m.Lives_ > 0 || Rules.Versus {
 			m.DoRespawn(state, u)
-		} else if m.Man_ == res.Man_Whip {
+		} else if u == &state.Mans[0] {
+			// the first man in the roster decides when everyone comes back
 			allowRespawn := true
 			maxLives := m.Lives_
 			for i := range state.Mans {
@@ -172,7 +211,10 @@ func (m *ManUnitData) UpdateDead(state *State, u *Unit) {
 }
 
 func (m *ManUnitData) DoRespawn(state *State, u *Unit) {
-	m.Lives_--
+	if !Rules.Versus {
+		m.Lives_--
+	}
+	m.HurtBy_ = UnitRef{}
 	u.Health = u.MaxHealth(state, u)
 	m.Crouching_ = false
 	u.Acceleration = Coord{}
@@ -240,7 +282,7 @@ func (m *ManUnitData) Update(state *State, u *Unit) {
 		}
 	}
 	if onGround && u.Velocity.Y == 0 && m.Input_.GetKeyUp() == res.Button_pressed {
-		u.Acceleration.Y = -ManData[m.Man()].JumpSpeed
+		u.Acceleration.Y = -ManData[m.Man()].JumpSpeed - state.DensityBoost(u)
 	} else {
 		u.Acceleration.Y = 0
 	}
@@ -302,6 +344,9 @@ func (m *ManUnitData) CollideWith(state *State, u, o *Unit) bool {
 func (m *ManUnitData) Ping() time.Duration {
 	return m.Ping_
 }
+func (m *ManUnitData) Base() *ManUnitData {
+	return m
+}
 func (m *ManUnitData) MaxHealth(state *State, u *Unit) int64 {
 	return ManData[m.Man()].MaxHealth
 }
@@ -321,6 +366,16 @@ type WhipMan struct {
 	WhipEnd    Coord
 	WhipTether Coord
 	WhipPull   bool
+	WhipLength int64      // length of the rope, set when it latches
+	WhipWraps  []WhipWrap // corners the rope bends around, starting at the tether
+	WhipTied   UnitRef    // teammate being towed on the rope instead of a tether
+}
+
+// WhipWrap is a corner a WhipMan's rope is wrapped around. Turn is the sign of
+// the bend so the rope can unwrap when the man swings back past it.
+type WhipWrap struct {
+	Coord
+	Turn int64
 }
 
 func (m *WhipMan) UpdateDead(state *State, u *Unit) {
@@ -331,30 +386,31 @@ func (m *WhipMan) UpdateDead(state *State, u *Unit) {
 	m.WhipEnd = Coord{}
 	m.WhipTether = Coord{}
 	m.WhipPull = false
+	m.WhipLength = 0
+	m.WhipWraps = nil
+	m.WhipTied = UnitRef{}
 }
 
 func (m *WhipMan) Update(state *State, u *Unit) {
-	m.ManUnitData.Update(state, u)
+	if m.WhipTether.Zero() {
+		m.ManUnitData.Update(state, u)
+	} else {
+		// up and down work the rope instead of jumping and crouching.
+		input := m.Input_
+		if input != nil {
+			keys := *input
+			keys.KeyUp, keys.KeyDown = nil, nil
+			m.Input_ = &keys
+		}
+		m.ManUnitData.Update(state, u)
+		m.Input_ = input
+
+		m.Swing(state, u)
+	}
 
 	if m.WhipStop != 0 && (m.WhipStop-m.WhipStart)/10 < state.Tick-m.WhipStop {
 		m.WhipStart, m.WhipStop, m.WhipEnd = 0, 0, Coord{}
 	}
-	if !m.WhipTether.Zero() {
-		if u.Position.Sub(m.WhipTether).LengthSquared() < WhipDistance*WhipDistance {
-			if ok, _ := u.OnGround(state); !ok {
-				if u.Velocity.LengthSquared() > TileSize*PixelSize*TileSize*PixelSize {
-					u.Velocity.X = u.Velocity.X * 19 / 20
-					u.Velocity.Y = u.Velocity.Y * 19 / 20
-				}
-				u.Velocity.X += (m.WhipTether.X - u.Position.X) / 100
-				u.Velocity.Y += (m.WhipTether.Y - u.Position.Y) / 100
-				u.Acceleration.X /= 4
-				u.Acceleration.Y /= 4
-			}
-		} else if m.WhipStop == 0 {
-			m.WhipTether = Coord{}
-		}
-	}
 	m1, m2 := m.Input_.GetMouse1() == res.Button_pressed, m.Input_.GetMouse2() == res.Button_pressed
 	if m1 || m2 {
 		m.WhipPull = m2
@@ -365,19 +421,27 @@ func (m *WhipMan) Update(state *State, u *Unit) {
 		if m.WhipStop == 0 {
 			m.WhipStop = state.Tick
 
-			if m.WhipStart < m.WhipStop-WhipTimeMax {
-				m.WhipStart = m.WhipStop - WhipTimeMax
+			if m.WhipStart < m.WhipStop-Tuning.WhipTimeMax {
+				m.WhipStart = m.WhipStop - Tuning.WhipTimeMax
 			}
 
-			m.WhipTether = Coord{}
+			m.WhipTether, m.WhipLength, m.WhipWraps, m.WhipTied = Coord{}, 0, nil, UnitRef{}
 			velocity, whipEnd, hurt, collide, hitWorld := m.Whip(state, u)
 			m.WhipEnd = whipEnd
-			collide.Hurt(state, u, hurt)
+			collide.Hurt(state, u, hurt, DamageWhip)
 
-			if m.WhipPull {
+			if m.WhipPull && collide != nil && collide.IsMan() {
+				// tie the rope to the teammate and tow them along.
+				collide.Velocity = collide.Velocity.Add(velocity)
+				m.WhipTied = state.Ref(collide)
+				m.WhipTether = collide.Position.Sub(Coord{0, collide.Size(state, collide).Y / 2})
+				m.WhipLength = int64(math.Sqrt(float64(m.Hand(state, u).Sub(m.WhipTether).LengthSquared())))
+				state.comboFloater(u, collide, "TOW!")
+			} else if m.WhipPull {
 				u.Velocity = u.Velocity.Sub(velocity)
 				if collide == nil && hitWorld {
 					m.WhipTether = whipEnd
+					m.WhipLength = int64(math.Sqrt(float64(m.Hand(state, u).Sub(whipEnd).LengthSquared())))
 				}
 			} else if collide != nil {
 				collide.Velocity = collide.Velocity.Add(velocity)
@@ -386,18 +450,178 @@ func (m *WhipMan) Update(state *State, u *Unit) {
 	}
 }
 
-func (m *WhipMan) Gravity(state *State, u *Unit) int64 {
-	g := m.ManUnitData.Gravity(state, u)
+// Hand is where the rope is attached to the WhipMan.
+func (m *WhipMan) Hand(state *State, u *Unit) Coord {
+	return u.Position.Sub(Coord{0, u.Size(state, u).Y / 2})
+}
+
+// Pivot is the point the WhipMan swings around: the last corner the rope is
+// wrapped around, or the tether if it is straight.
+func (m *WhipMan) Pivot() Coord {
+	if len(m.WhipWraps) == 0 {
+		return m.WhipTether
+	}
+	return m.WhipWraps[len(m.WhipWraps)-1].Coord
+}
+
+// wrapped is the length of rope between the tether and the pivot.
+func (m *WhipMan) wrapped() int64 {
+	var length float64
+	prev := m.WhipTether
+	for _, w := range m.WhipWraps {
+		length += math.Sqrt(float64(w.Sub(prev).LengthSquared()))
+		prev = w.Coord
+	}
+	return int64(length)
+}
+
+func cross(a, b Coord) int64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// Swing keeps a tethered WhipMan within reach of the rope. Only the part of
+// the velocity that would stretch the rope is removed, so swinging keeps its
+// speed.
+func (m *WhipMan) Swing(state *State, u *Unit) {
+	if !m.WhipTied.Zero() {
+		m.Tow(state, u)
+		return
+	}
+
+	hand := m.Hand(state, u)
+
+	// unwrap from corners the rope has swung back past.
+	for len(m.WhipWraps) != 0 {
+		w := m.WhipWraps[len(m.WhipWraps)-1]
+		prev := m.WhipTether
+		if len(m.WhipWraps) > 1 {
+			prev = m.WhipWraps[len(m.WhipWraps)-2].Coord
+		}
+		if cross(w.Sub(prev), hand.Sub(w.Coord))*w.Turn > 0 {
+			break
+		}
+		m.WhipWraps = m.WhipWraps[:len(m.WhipWraps)-1]
+	}
 
-	if !m.WhipTether.Zero() && u.Position.Sub(m.WhipTether).LengthSquared() < WhipDistance*WhipDistance {
-		if ok, _ := u.OnGround(state); !ok {
-			if u.Position.Y > m.WhipTether.Y {
-				g -= Gravity * 9 / 10
+	// wrap around a corner that is in the way.
+	pivot := m.Pivot()
+	if tr := state.Trace(pivot, hand, Coord{1, 1}, true); tr.HitWorld {
+		if corner, ok := ropeCorner(state, pivot, hand, tr); ok {
+			if turn := cross(corner.Sub(pivot), hand.Sub(corner)); turn != 0 {
+				if turn > 0 {
+					turn = 1
+				} else {
+					turn = -1
+				}
+				m.WhipWraps = append(m.WhipWraps, WhipWrap{corner, turn})
+				pivot = corner
 			}
 		}
 	}
 
-	return g
+	wrapped := m.wrapped()
+	m.reel(wrapped + TileSize*PixelSize)
+	free := float64(m.WhipLength - wrapped)
+
+	delta := hand.Sub(pivot)
+	dist := math.Sqrt(float64(delta.LengthSquared()))
+	if dist < free || dist == 0 {
+		return
+	}
+
+	if radial := float64(u.Velocity.X*delta.X+u.Velocity.Y*delta.Y) / dist; radial > 0 {
+		u.Velocity.X -= int64(radial * float64(delta.X) / dist)
+		u.Velocity.Y -= int64(radial * float64(delta.Y) / dist)
+	}
+
+	if dist > free {
+		tr := state.Trace(u.Position, u.Position.Sub(Scale(delta, dist-free)), u.Size(state, u), false)
+		tr.CollideWith(state, u)
+		u.Position = tr.End
+	}
+}
+
+// Tow keeps a teammate tied to the WhipMan's rope within reach, dragging them
+// along behind.
+func (m *WhipMan) Tow(state *State, u *Unit) {
+	o := state.Deref(m.WhipTied)
+	if o == nil || o.Health <= 0 {
+		m.WhipTied, m.WhipTether, m.WhipLength = UnitRef{}, Coord{}, 0
+		return
+	}
+	m.WhipTether = o.Position.Sub(Coord{0, o.Size(state, o).Y / 2})
+
+	m.reel(TileSize * PixelSize)
+	free := float64(m.WhipLength)
+
+	delta := m.WhipTether.Sub(m.Hand(state, u))
+	dist := math.Sqrt(float64(delta.LengthSquared()))
+	if dist <= free {
+		return
+	}
+
+	rel := o.Velocity.Sub(u.Velocity)
+	if radial := float64(rel.X*delta.X+rel.Y*delta.Y) / dist; radial > 0 {
+		o.Velocity.X -= int64(radial * float64(delta.X) / dist)
+		o.Velocity.Y -= int64(radial * float64(delta.Y) / dist)
+	}
+
+	tr := state.Trace(o.Position, o.Position.Sub(Scale(delta, dist-free)), o.Size(state, o), false)
+	tr.CollideWith(state, o)
+	o.Position = tr.End
+	state.UnitMoved(o)
+}
+
+// reel lets the rope out or pulls it in while up or down is held, keeping it
+// at least min long and no longer than the whip reaches.
+func (m *WhipMan) reel(min int64) {
+	if m.Input_.GetKeyUp() == res.Button_pressed {
+		m.WhipLength -= Tuning.WhipReel / TicksPerSecond
+	}
+	if m.Input_.GetKeyDown() == res.Button_pressed {
+		m.WhipLength += Tuning.WhipReel / TicksPerSecond
+	}
+	if m.WhipLength > Tuning.WhipDistance {
+		m.WhipLength = Tuning.WhipDistance
+	}
+	if m.WhipLength < min {
+		m.WhipLength = min
+	}
+}
+
+// ropeCorner finds the corner of the tile hit by tr, a trace along a rope from
+// one point to another, that the rope should bend around.
+func ropeCorner(state *State, from, to Coord, tr *Trace) (Coord, bool) {
+	const size = TileSize * PixelSize
+
+	// step from the 1x1 hull into the wall to find the tile that was hit.
+	n := tr.Side.Normal()
+	tile := Coord{tr.End.X - 1 - n.X*2, tr.End.Y - 1 - n.Y*2}.Floor(size)
+
+	line := to.Sub(from)
+	length := math.Sqrt(float64(line.LengthSquared()))
+	if length == 0 {
+		return Coord{}, false
+	}
+
+	var best Coord
+	bestDist := math.Inf(1)
+	for _, c := range [...]Coord{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		// just outside the corner so the rope doesn't start inside the tile.
+		corner := Coord{tile.X + c.X*size + (c.X*2-1)*PixelSize, tile.Y + c.Y*size + (c.Y*2-1)*PixelSize}
+		if pos := corner.Floor(size); state.Solid(pos.X/size, pos.Y/size) {
+			continue
+		}
+		if state.Trace(from, corner, Coord{1, 1}, true).HitWorld {
+			continue
+		}
+		d := math.Abs(float64(cross(line, corner.Sub(from)))) / length
+		if d < bestDist {
+			best, bestDist = corner, d
+		}
+	}
+
+	return best, !math.IsInf(bestDist, 1)
 }
 
 func (m *WhipMan) Whip(state *State, u *Unit) (velocity, whipEnd Coord, hurt int64, collide *Unit, hitWorld bool) {
@@ -405,29 +629,29 @@ func (m *WhipMan) Whip(state *State, u *Unit) (velocity, whipEnd Coord, hurt int
 		return
 	}
 	t := state.Tick - m.WhipStart
-	if t > WhipTimeMax {
-		t = WhipTimeMax
+	if t > Tuning.WhipTimeMax {
+		t = Tuning.WhipTimeMax
 	}
-	if t < WhipTimeMin {
+	if t < Tuning.WhipTimeMin {
 		return
 	}
 
 	start := u.Position
 	start.Y -= u.Size(state, u).Y / 2
 	delta := m.Target().Sub(start)
-	stop := start.Add(Scale(delta, WhipDistance))
+	stop := start.Add(Scale(delta, float64(Tuning.WhipDistance)))
 
 	tr := state.Trace(start, stop, Coord{1, 1}, false)
 	collide = tr.Collide(u)
 	whipEnd = tr.End
 	hitWorld = tr.HitWorld
 
-	if collide != nil && !collide.IsMan() {
-		hurt = Lerp(WhipDamageMin, WhipDamageMax, WhipTimeMin, WhipTimeMax, t)
+	if collide != nil {
+		hurt = Lerp(Tuning.WhipDamageMin, Tuning.WhipDamageMax, Tuning.WhipTimeMin, Tuning.WhipTimeMax, t)
 	}
 
 	if start != tr.End && (collide != nil || tr.HitWorld) {
-		velocity = Scale(start.Sub(tr.End), float64(Lerp(WhipSpeedMin, WhipSpeedMax, WhipTimeMin, WhipTimeMax, t)))
+		velocity = Scale(start.Sub(tr.End), float64(Lerp(Tuning.WhipSpeedMin, Tuning.WhipSpeedMax, Tuning.WhipTimeMin, Tuning.WhipTimeMax, t)))
 	}
 
 	return
@@ -435,7 +659,9 @@ func (m *WhipMan) Whip(state *State, u *Unit) (velocity, whipEnd Coord, hurt int
 
 type DensityMan struct {
 	ManUnitData
-	Gravity_ int64
+	Gravity_    int64
+	LaunchedBy_ UnitRef // VacuumMan that fired this man
+	Launched_   uint64
 }
 
 func (m *DensityMan) Update(state *State, u *Unit) {
@@ -449,18 +675,80 @@ func (m *DensityMan) Update(state *State, u *Unit) {
 	if m.Input_.GetMouse2() == res.Button_pressed {
 		m.Gravity_ -= 10
 	}
-	if m.Gravity_ < -Gravity {
-		m.Gravity_ = -Gravity
+	if m.Gravity_ < Tuning.DensityMin {
+		m.Gravity_ = Tuning.DensityMin
 	}
-	if m.Gravity_ > Gravity*4 {
-		m.Gravity_ = Gravity * 4
+	if m.Gravity_ > Tuning.DensityMax {
+		m.Gravity_ = Tuning.DensityMax
 	}
 }
 
 func (m *DensityMan) Gravity(state *State, u *Unit) int64 {
+	if state.InLiquid(u) {
+		// the liquid holds up a man of normal density.
+		return m.Gravity_
+	}
 	return m.Gravity_ + Gravity
 }
 
+// GroundPound is called when u lands on the ground at the given speed. A
+// dense enough DensityMan hurts and throws the units around it and breaks
+// fragile tiles instead of hurting itself.
+func (state *State) GroundPound(u *Unit, speed int64) bool {
+	m, ok := u.UnitData.(*DensityMan)
+	if !ok || m.Gravity_ < Tuning.PoundDensity || speed < Tuning.PoundSpeed {
+		return false
+	}
+
+	radius := Tuning.PoundRadius
+	mass := u.Mass(state, u)
+	center := u.Position
+
+	state.EachUnitIn(center.Sub(Coord{radius, radius}), center.Add(Coord{radius, radius}), func(o *Unit) {
+		if o == u || o.Health <= 0 {
+			return
+		}
+		delta := o.Position.Sub(center)
+		dist := int64(math.Sqrt(float64(delta.LengthSquared())))
+		if dist >= radius {
+			return
+		}
+		o.Hurt(state, u, speed*mass/DamageFactor*(radius-dist)/radius, DamagePound)
+		if delta.Zero() {
+			delta.Y = -1
+		}
+		push := Scale(delta, float64(speed*(radius-dist)/radius))
+		push.Y -= speed * (radius - dist) / radius / 2
+		o.Velocity = o.Velocity.Add(push)
+	})
+
+	const size = TileSize * PixelSize
+	min := center.Sub(Coord{radius, radius}).Floor(size)
+	max := center.Add(Coord{radius, radius}).Floor(size)
+	for x := min.X / size; x <= max.X/size; x++ {
+		for y := min.Y / size; y <= max.Y/size; y++ {
+			if state.world.Special(x, y) != SpecialTile_Fragile || !state.Solid(x, y) {
+				continue
+			}
+			d := Coord{x*size + size/2, y*size + size/2}.Sub(center)
+			if d.LengthSquared() < radius*radius {
+				state.BreakTile(x, y)
+			}
+		}
+	}
+
+	state.Floaters = append(state.Floaters, Floater{
+		S:  "POUND!",
+		Fg: color.RGBA{255, 255, 255, 255},
+		Bg: u.Color(state, u),
+		X:  center.X,
+		Y:  center.Y,
+		T:  state.Tick,
+	})
+
+	return true
+}
+
 func (m *DensityMan) Mass(state *State, u *Unit) int64 {
 	mass := m.ManUnitData.Mass(state, u)
 	return mass + mass*m.Gravity_/Gravity
@@ -468,64 +756,117 @@ func (m *DensityMan) Mass(state *State, u *Unit) int64 {
 
 type VacuumMan struct {
 	ManUnitData
-	Held_      uint64
+	Held_      []UnitRef // the last unit sucked up is fired first
 	HeldSince_ uint64
 	LastLemon_ uint64
+	Lemons_    int64
+	Sucking_   bool
+	Firing_    bool
 }
 
 func (m *VacuumMan) UpdateDead(state *State, u *Unit) {
 	m.ManUnitData.UpdateDead(state, u)
 
-	if h := m.Held(state); h != nil {
+	m.Drop(state, u)
+	m.Lemons_ = Tuning.LemonAmmo
+}
+
+// Drop lets go of the held units, leaving them where the VacuumMan is.
+func (m *VacuumMan) Drop(state *State, u *Unit) {
+	held := m.Held(state)
+	for _, h := range held {
 		h.Position = u.Position
 		h.Velocity = Coord{}
 	}
 
-	m.Held_, m.HeldSince_ = 0, 0
+	m.Held_, m.HeldSince_, m.Firing_ = nil, 0, false
+
+	for _, h := range held {
+		state.UnitMoved(h)
+	}
+}
+
+// Held returns the units the VacuumMan is holding, bottom of the stack first.
+func (m *VacuumMan) Held(state *State) []*Unit {
+	var held []*Unit
+	for _, r := range m.Held_ {
+		if h := state.Deref(r); h != nil {
+			held = append(held, h)
+		}
+	}
+	return held
 }
 
-func (m *VacuumMan) Held(state *State) *Unit {
-	if m.Held_ == 0 {
+// top returns the unit that will be fired next.
+func (m *VacuumMan) top(state *State) *Unit {
+	if len(m.Held_) == 0 {
 		return nil
 	}
-	if m.Held_ <= uint64(res.Man_count) {
-		return &state.Mans[m.Held_-1]
+	return state.Deref(m.Held_[len(m.Held_)-1])
+}
+
+func (m *VacuumMan) pop() {
+	m.Held_ = m.Held_[:len(m.Held_)-1]
+}
+
+// Suck takes in o, which is close enough to the nozzle to be picked up.
+// Lemons and grubs become ammo. Anything else goes on the stack if there
+// is room.
+func (m *VacuumMan) Suck(state *State, u, o *Unit) {
+	var lemons int64
+	switch o.UnitData.(type) {
+	case *Lemon:
+		lemons = 1
+	case *Grub:
+		lemons = Tuning.GrubLemons
+	default:
+		if int64(len(m.Held_)) >= Tuning.VacuumHold {
+			return
+		}
+		if r := state.Ref(o); !r.Zero() {
+			m.Held_ = append(m.Held_, r)
+			m.HeldSince_ = state.Tick
+		}
+		return
+	}
+
+	if m.Lemons_ >= Tuning.LemonAmmoMax {
+		return
+	}
+	m.Lemons_ += lemons
+	if m.Lemons_ > Tuning.LemonAmmoMax {
+		m.Lemons_ = Tuning.LemonAmmoMax
+	}
+	if r := state.Ref(o); r.Unit != 0 {
+		// it is gone for the rest of the tick too.
+		o.Health = 0
+		delete(state.Units, r.Unit-1)
+		state.UnitRemoved(o)
 	}
-	return state.Units[m.Held_-uint64(res.Man_count)]
 }
 
 func (m *VacuumMan) Update(state *State, u *Unit) {
 	m.ManUnitData.Update(state, u)
 
-	if m.Input_.GetMouse2() == res.Button_pressed {
+	sucking := m.Input_.GetMouse2() == res.Button_pressed
+	if m.Sucking_ && !sucking && len(m.Held_) != 0 {
+		m.Firing_ = true
+	}
+	m.Sucking_ = sucking
+
+	if sucking {
 		start := u.Position.Sub(Coord{0, u.Size(state, u).Y / 2})
-		delta := Scale(m.Target().Sub(start), VacuumDistance)
+		delta := Scale(m.Target().Sub(start), float64(Tuning.VacuumDistance))
 		tr := state.Trace(start, start.Add(delta), Coord{1, 1}, false)
-		if collide := tr.CollideWith(state, u); collide != nil {
-			if m.Held_ == 0 {
-				if collide.Position.Sub(Coord{0, collide.Size(state, collide).Y / 2}).Sub(start).LengthSquared() < (u.Size(state, collide).X+collide.Size(state, collide).X)*(u.Size(state, u).X+collide.Size(state, collide).X) {
-					for i := range state.Mans {
-						if collide == &state.Mans[i] {
-							m.Held_ = uint64(i) + 1
-							m.HeldSince_ = state.Tick
-							break
-						}
-					}
-					if m.Held_ == 0 {
-						for i, c := range state.Units {
-							if collide == c {
-								m.Held_ = i + uint64(res.Man_count)
-								m.HeldSince_ = state.Tick
-								break
-							}
-						}
-					}
-				}
+		// lemons go through the VacuumMan, but they can still be sucked up.
+		if collide := tr.Collide(u); collide != nil {
+			if collide.Position.Sub(Coord{0, collide.Size(state, collide).Y / 2}).Sub(start).LengthSquared() < (u.Size(state, collide).X+collide.Size(state, collide).X)*(u.Size(state, u).X+collide.Size(state, collide).X) {
+				m.Suck(state, u, collide)
 			}
-			collide.Velocity = collide.Velocity.Sub(Coord{delta.X / VacuumSuck, delta.Y / VacuumSuck})
+			collide.Velocity = collide.Velocity.Sub(Coord{delta.X / Tuning.VacuumSuck, delta.Y / Tuning.VacuumSuck})
 		}
-	} else if m.Held_ != 0 {
-		if h := m.Held(state); h != nil {
+	} else if m.Firing_ {
+		if h := m.top(state); h != nil {
 			h.Position = u.Position
 			h.Position.Y--
 			if m.Target().X > u.Position.X {
@@ -533,22 +874,31 @@ func (m *VacuumMan) Update(state *State, u *Unit) {
 			} else {
 				h.Position.X -= u.Size(state, u).X/2 + h.Size(state, h).X/2 + PixelSize
 			}
-			h.Velocity = Scale(m.Target().Sub(u.Position).Add(Coord{0, u.Size(state, u).Y / 2}), VacuumSpeed*float64(state.Tick-m.HeldSince_)).Add(u.Velocity)
+			h.Velocity = Scale(m.Target().Sub(u.Position).Add(Coord{0, u.Size(state, u).Y / 2}), float64(Tuning.VacuumSpeed)*float64(state.Tick-m.HeldSince_)).Add(u.Velocity)
 			tr := state.Trace(h.Position, h.Position.Add(h.Velocity.Unit()), h.Size(state, h), false)
 			collide := tr.CollideFunc(func(o *Unit) bool {
 				return o != u && h.CollideWith(state, h, o)
 			})
 			if collide == nil && !tr.HitWorld {
-				m.Held_, m.HeldSince_ = 0, 0
+				m.pop()
+				m.Firing_ = false
+				m.HeldSince_ = state.Tick
+				state.UnitMoved(h)
+				if d, ok := h.UnitData.(*DensityMan); ok {
+					d.LaunchedBy_, d.Launched_ = state.Ref(u), state.Tick
+				}
 			}
 		} else {
-			m.Held_, m.HeldSince_ = 0, 0
+			m.pop()
 		}
 	} else if m.Input_.GetMouse1() == res.Button_pressed {
-		if state.Tick-m.LastLemon_ > LemonTime {
+		if state.Tick-m.LastLemon_ > Tuning.LemonTime && m.Lemons_ > 0 {
 			lemon := &Unit{
-				Health:   1,
-				UnitData: &Lemon{state.NextUnit},
+				Health: 1,
+				UnitData: &Lemon{
+					ID:      state.NextUnit,
+					Expires: state.Tick + Tuning.LemonLife,
+				},
 			}
 			lemon.Position = u.Position
 			lemon.Position.Y -= u.Size(state, u).Y / 2
@@ -557,27 +907,32 @@ func (m *VacuumMan) Update(state *State, u *Unit) {
 			} else {
 				lemon.Position.X -= u.Size(state, u).X/2 + (*Lemon).Size(nil, nil, nil).X/2 + PixelSize
 			}
-			lemon.Velocity = Scale(m.Target().Sub(u.Position).Add(Coord{0, u.Size(state, u).Y / 2}), LemonSpeed).Add(u.Velocity)
+			lemon.Velocity = Scale(m.Target().Sub(u.Position).Add(Coord{0, u.Size(state, u).Y / 2}), float64(Tuning.LemonSpeed)).Add(u.Velocity)
 			tr := state.Trace(lemon.Position, lemon.Position.Add(lemon.Velocity.Unit()), lemon.Size(state, lemon), false)
 			collide := tr.CollideWith(state, lemon)
 
 			if collide == nil && !tr.HitWorld {
 				state.Units[state.NextUnit] = lemon
 				state.NextUnit++
+				state.UnitMoved(lemon)
+				m.Lemons_--
 			}
 			m.LastLemon_ = state.Tick
 		}
 	}
 
+	if len(m.Held_) == 0 {
+		m.HeldSince_, m.Firing_ = 0, false
+	}
 	if m.HeldSince_ != 0 {
-		u.Hurt(state, m.Held(state), int64(state.Tick-m.HeldSince_)/VacuumHurt)
+		u.Hurt(state, m.top(state), int64(state.Tick-m.HeldSince_)/Tuning.VacuumHurt, DamageVacuum)
 	}
 }
 
 func (m *VacuumMan) Mass(state *State, u *Unit) int64 {
 	mass := m.ManUnitData.Mass(state, u)
 
-	if h := m.Held(state); h != nil {
+	for _, h := range m.Held(state) {
 		mass += h.Mass(state, u)
 	}
 
@@ -595,7 +950,8 @@ func (*VacuumMan) CollideWith(state *State, u, o *Unit) bool {
 }
 
 type Lemon struct {
-	ID uint64
+	ID      uint64
+	Expires uint64 // tick the lemon goes away at, or 0 to last until destroyed
 }
 
 func (*Lemon) Color(*State, *Unit) color.RGBA {
@@ -626,7 +982,11 @@ func (*Lemon) Size(*State, *Unit) Coord {
 	return Coord{16 * PixelSize, 16 * PixelSize}
 }
 
-func (*Lemon) Update(*State, *Unit) {}
+func (l *Lemon) Update(state *State, u *Unit) {
+	if l.Expires != 0 && l.Expires <= state.Tick {
+		u.Health = 0
+	}
+}
 
 func (l *Lemon) UpdateDead(state *State, u *Unit) {
 	delete(state.Units, l.ID)
@@ -642,6 +1002,84 @@ func (*Lemon) CollideWith(state *State, u, o *Unit) bool {
 	return true
 }
 
+// BounceLemon makes u bounce off the wall it hit if it is a lemon.
+func (state *State) BounceLemon(u *Unit, tr *Trace) bool {
+	if _, ok := u.UnitData.(*Lemon); !ok {
+		return false
+	}
+
+	n := tr.Side.Normal()
+	if tr.Side == SideTop && tr.Shape.Sloped() {
+		n = tr.Shape.Normal()
+	}
+	if dot := u.Velocity.X*n.X + u.Velocity.Y*n.Y; dot < 0 {
+		// take away the part going into the wall and send some of it back.
+		bounce := dot * (100 + Tuning.LemonBounce) / 100
+		u.Velocity.X -= bounce * n.X / n.LengthSquared()
+		u.Velocity.Y -= bounce * n.Y / n.LengthSquared()
+	}
+	return true
+}
+
 type NormalMan struct {
 	ManUnitData
+	Shield_     uint64 // tick the shield bubble pops at
+	ShieldLeft_ int64  // damage the shield bubble can still absorb
+	Cooldown_   uint64 // tick the shield bubble can be used again at
+	Activating_ bool
+}
+
+func (m *NormalMan) UpdateDead(state *State, u *Unit) {
+	m.ManUnitData.UpdateDead(state, u)
+
+	m.Shield_, m.ShieldLeft_ = 0, 0
+}
+
+func (m *NormalMan) Update(state *State, u *Unit) {
+	m.ManUnitData.Update(state, u)
+
+	pressed := m.Input_.GetMouse1() == res.Button_pressed || m.Input_.GetMouse2() == res.Button_pressed
+	if pressed && !m.Activating_ && m.Cooldown_ <= state.Tick {
+		m.Shield_ = state.Tick + Tuning.ShieldTime
+		m.ShieldLeft_ = Tuning.ShieldAbsorb
+		m.Cooldown_ = state.Tick + Tuning.ShieldCooldown
+	}
+	m.Activating_ = pressed
+
+	if m.Shield_ <= state.Tick || m.ShieldLeft_ <= 0 {
+		m.Shield_, m.ShieldLeft_ = 0, 0
+	}
+}
+
+// Shielding reports whether the NormalMan's shield bubble is up.
+func (m *NormalMan) Shielding() bool {
+	return m.Shield_ != 0
+}
+
+// Shield lets the shield bubbles of nearby NormalMans absorb damage that
+// would be dealt to the man u. It returns the damage that gets through.
+func (state *State) Shield(u *Unit, amount int64) int64 {
+	middle := u.Position.Sub(Coord{0, u.Size(state, u).Y / 2})
+	for i := range state.Mans {
+		nu := &state.Mans[i]
+		m, ok := nu.UnitData.(*NormalMan)
+		if !ok || !m.Shielding() || nu.Health <= 0 {
+			continue
+		}
+		d := middle.Sub(nu.Position.Sub(Coord{0, nu.Size(state, nu).Y / 2}))
+		if d.LengthSquared() > Tuning.ShieldRadius*Tuning.ShieldRadius {
+			continue
+		}
+
+		absorb := amount
+		if absorb > m.ShieldLeft_ {
+			absorb = m.ShieldLeft_
+		}
+		m.ShieldLeft_ -= absorb
+		amount -= absorb
+		if amount <= 0 {
+			break
+		}
+	}
+	return amount
 }
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"github.com/Rnoadm/wdvn/res"
+	"image"
+)
+
+const (
+	PortalTiles = 3         // the number of tiles of wall a portal covers
+	PortalSlack = PixelSize // how far from the wall a unit can stop and still be touching it
+)
+
+// Portal is one end of a PortalMan's pair of teleporters. Position is the
+// middle of the portal on the surface of the wall and Side is the side of the
+// wall it is on. A placed portal never has a zero Position.
+type Portal struct {
+	Position Coord
+	Side
+}
+
+func (p Portal) Placed() bool {
+	return !p.Position.Zero()
+}
+
+// Normal returns the direction the surface on this side of a tile faces.
+func (s Side) Normal() Coord {
+	switch s {
+	case SideTop:
+		return Coord{0, -1}
+	case SideBottom:
+		return Coord{0, 1}
+	case SideLeft:
+		return Coord{-1, 0}
+	case SideRight:
+		return Coord{1, 0}
+	}
+	return Coord{}
+}
+
+// Overlaps reports whether both portals are placed on the same wall close
+// enough together to share tiles.
+func (p Portal) Overlaps(o Portal) bool {
+	if !p.Placed() || !o.Placed() || p.Side != o.Side {
+		return false
+	}
+	d := p.Position.Sub(o.Position)
+	n := p.Side.Normal()
+	if d.X*n.X+d.Y*n.Y != 0 {
+		return false
+	}
+	along := d.X*n.Y - d.Y*n.X
+	if along < 0 {
+		along = -along
+	}
+	return along < PortalTiles*TileSize*PixelSize
+}
+
+// Touching reports whether a unit whose hull covers min to max is pressed
+// against the portal with its middle inside it. Trace can stop a unit just
+// short of the wall, so it only has to be within PortalSlack of it.
+func (p Portal) Touching(min, max Coord) bool {
+	const reach = PortalTiles * TileSize * PixelSize / 2
+
+	against := func(a, b int64) bool {
+		return a-b <= PortalSlack && b-a <= PortalSlack
+	}
+
+	mid := Coord{(min.X + max.X) / 2, (min.Y + max.Y) / 2}.Sub(p.Position)
+	switch p.Side {
+	case SideTop:
+		return against(max.Y, p.Position.Y) && mid.X >= -reach && mid.X <= reach
+	case SideBottom:
+		return against(min.Y, p.Position.Y) && mid.X >= -reach && mid.X <= reach
+	case SideLeft:
+		return against(max.X, p.Position.X) && mid.Y >= -reach && mid.Y <= reach
+	case SideRight:
+		return against(min.X, p.Position.X) && mid.Y >= -reach && mid.Y <= reach
+	}
+	return false
+}
+
+// Through returns where a unit of the given size that went into p at the
+// given velocity comes out of o. The velocity is turned so the unit leaves o
+// the way it went into p. Sideways movement keeps its direction when both
+// walls are parallel.
+func (p Portal) Through(o Portal, size, velocity Coord) (position, newVelocity Coord) {
+	min, max := size.Hull()
+	position = o.Position
+	switch o.Side {
+	case SideTop:
+		position.Y -= PixelSize + max.Y
+	case SideBottom:
+		position.Y += PixelSize - min.Y
+	case SideLeft:
+		position.X -= PixelSize + max.X
+		position.Y -= (min.Y + max.Y) / 2
+	case SideRight:
+		position.X += PixelSize - min.X
+		position.Y -= (min.Y + max.Y) / 2
+	}
+
+	in, out := p.Side.Normal(), o.Side.Normal()
+	inAlong, outAlong := Coord{-in.Y, in.X}, Coord{out.Y, -out.X}
+	if inAlong.X*outAlong.X+inAlong.Y*outAlong.Y != 0 {
+		outAlong = inAlong
+	}
+	speed := -(velocity.X*in.X + velocity.Y*in.Y)
+	sideways := velocity.X*inAlong.X + velocity.Y*inAlong.Y
+	newVelocity = Coord{
+		speed*out.X + sideways*outAlong.X,
+		speed*out.Y + sideways*outAlong.Y,
+	}
+	return
+}
+
+// Rect returns the area covered by the portal in pixels.
+func (p Portal) Rect() image.Rectangle {
+	const depth, half = 3, PortalTiles * TileSize / 2
+
+	x, y := int(p.Position.X/PixelSize), int(p.Position.Y/PixelSize)
+	switch p.Side {
+	case SideTop:
+		return image.Rect(x-half, y-depth, x+half, y)
+	case SideBottom:
+		return image.Rect(x-half, y, x+half, y+depth)
+	case SideLeft:
+		return image.Rect(x-depth, y-half, x, y+half)
+	case SideRight:
+		return image.Rect(x, y-half, x+depth, y+half)
+	}
+	return image.Rectangle{}
+}
+
+type PortalMan struct {
+	ManUnitData
+	Portals_ [2]Portal
+	Placing_ [2]bool
+}
+
+func (m *PortalMan) Update(state *State, u *Unit) {
+	m.ManUnitData.Update(state, u)
+
+	for i, pressed := range [...]bool{m.Input_.GetMouse1() == res.Button_pressed, m.Input_.GetMouse2() == res.Button_pressed} {
+		if pressed && !m.Placing_[i] {
+			if p, ok := m.Aim(state, u); ok && !p.Overlaps(m.Portals_[1-i]) {
+				m.Portals_[i] = p
+			}
+		}
+		m.Placing_[i] = pressed
+	}
+}
+
+// Aim returns where a portal would be placed right now, if anywhere.
+func (m *PortalMan) Aim(state *State, u *Unit) (Portal, bool) {
+	start := u.Position
+	start.Y -= u.Size(state, u).Y / 2
+	stop := start.Add(Scale(m.Target().Sub(start), float64(Tuning.PortalDistance)))
+
+	tr := state.Trace(start, stop, Coord{1, 1}, false)
+	if tr.Collide(u) != nil || !tr.HitWorld {
+		return Portal{}, false
+	}
+
+	// step from the 1x1 hull into the wall to find the tile that was hit.
+	n := tr.Side.Normal()
+	tile := Coord{tr.End.X - 1 - n.X*2, tr.End.Y - 1 - n.Y*2}.Floor(TileSize * PixelSize)
+	tx, ty := tile.X/TileSize/PixelSize, tile.Y/TileSize/PixelSize
+
+	// the wall has to be flat and plain all the way along the portal with
+	// nothing solid in front of it.
+	for i := int64(-PortalTiles / 2); i <= PortalTiles/2; i++ {
+		x, y := tx-n.Y*i, ty+n.X*i
+		if !state.Solid(x, y) || state.world.Shape(x, y) != TileShape_Full || state.world.Special(x, y) != SpecialTile_None || state.Solid(x+n.X, y+n.Y) {
+			return Portal{}, false
+		}
+	}
+
+	const half = TileSize * PixelSize / 2
+	return Portal{
+		Position: Coord{tile.X + half + n.X*half, tile.Y + half + n.Y*half},
+		Side:     tr.Side,
+	}, true
+}
+
+// EnterPortal moves u out of the other end of a portal if tr, the trace it
+// moved along this tick, ended against one.
+func (state *State) EnterPortal(u *Unit, tr *Trace) bool {
+	size := u.Size(state, u)
+	min, max := size.Hull()
+	min, max = min.Add(tr.End), max.Add(tr.End)
+
+	for i := range state.Mans {
+		m, ok := state.Mans[i].UnitData.(*PortalMan)
+		if !ok || !m.Portals_[0].Placed() || !m.Portals_[1].Placed() {
+			continue
+		}
+		for j, p := range m.Portals_ {
+			if p.Side != tr.Side || !p.Touching(min, max) {
+				continue
+			}
+
+			position, velocity := p.Through(m.Portals_[1-j], size, u.Velocity)
+
+			// a trace that doesn't move only hits things that overlap the hull.
+			blocked := state.Trace(position, position, size, false)
+			if blocked.HitWorld || blocked.CollideWith(state, u) != nil {
+				continue
+			}
+
+			tr.End = position
+			u.Velocity = velocity
+			return true
+		}
+	}
+
+	return false
+}
@@ -167,3 +167,31 @@ func Write(conn net.Conn, packets <-chan *res.Packet, errors chan<- error) {
 func Send(ch chan<- *res.Packet, p *res.Packet) {
 	ch <- p
 }
+
+// Queue passes packets from in to out in the order they were sent, without
+// making the sender wait for out to be read. out is closed when in is, and
+// any packets not yet passed on are dropped.
+func Queue(in <-chan *res.Packet, out chan<- *res.Packet) {
+	defer close(out)
+
+	var queue []*res.Packet
+	for {
+		var next chan<- *res.Packet
+		var p *res.Packet
+		if len(queue) != 0 {
+			next, p = out, queue[0]
+		}
+
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return
+			}
+			queue = append(queue, v)
+
+		case next <- p:
+			queue[0] = nil
+			queue = queue[1:]
+		}
+	}
+}
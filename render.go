@@ -5,22 +5,30 @@ import (
 	"code.google.com/p/freetype-go/freetype/truetype"
 	"fmt"
 	"github.com/Rnoadm/wdvn/res"
+	"github.com/dustin/go-humanize"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/png"
 	"log"
+	"math"
 	"strings"
 	"sync"
 	"time"
 )
 
-func Render(img *image.RGBA, me res.Man, state *State, err error) {
+// TitleTime is how long the level's title is shown after it loads.
+const TitleTime = 3 * time.Second
+
+func Render(img *image.RGBA, me int, state *State, err error) {
+	tuningLock.RLock()
+	defer tuningLock.RUnlock()
+
 	hx, hy := (img.Rect.Min.X+img.Rect.Max.X)/2, (img.Rect.Min.Y+img.Rect.Max.Y)/2
 
 	draw.Draw(img, img.Rect, image.White, image.ZP, draw.Src)
 
-	if state == nil || state.world == nil || state.Mans[0].UnitData == nil {
+	if state == nil || state.world == nil || me >= len(state.Mans) {
 		RenderText(img, "Connecting...", image.Pt(hx, hy), color.Black, color.White, true)
 
 		if err != nil {
@@ -32,43 +40,90 @@ func Render(img *image.RGBA, me res.Man, state *State, err error) {
 
 	if *flagSplitScreen {
 		for i := range state.Mans {
-			r := img.Rect
-			if i&1 == 0 {
-				r.Max.Y = hy
-			} else {
-				r.Min.Y = hy
+			r := splitScreen(img.Rect, len(state.Mans), i)
+			render(img.SubImage(r).(*image.RGBA), i, state)
+			if r.Min.X != img.Rect.Min.X {
+				draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+1, r.Max.Y), image.Black, image.ZP, draw.Src)
 			}
-			if i&2 == 0 {
-				r.Max.X = hx
-			} else {
-				r.Min.X = hx
+			if r.Min.Y != img.Rect.Min.Y {
+				draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+1), image.Black, image.ZP, draw.Src)
 			}
-			render(img.SubImage(r).(*image.RGBA), res.Man(i), state)
 		}
-		draw.Draw(img, image.Rect(hx, img.Rect.Min.Y, hx+1, img.Rect.Max.Y), image.Black, image.ZP, draw.Src)
-		draw.Draw(img, image.Rect(img.Rect.Min.X, hy, img.Rect.Max.X, hy+1), image.Black, image.ZP, draw.Src)
 	} else {
 		render(img, me, state)
 	}
 
 	for i := range state.Mans {
 		m := state.Mans[i].UnitData.(Man)
-		x, y := 2, 12+4
-		if i&1 == 1 {
-			y = img.Rect.Dy() - 12*2 - 4
+		c := ManData[m.Man()].Color
+
+		// each man's status goes in the corner of its part of the screen
+		// nearest to the corner of the window.
+		var r image.Rectangle
+		if *flagSplitScreen {
+			r = splitScreen(img.Rect, len(state.Mans), i)
+		} else {
+			r = screenGrid(img.Rect, (len(state.Mans)+1)/2, 2, i)
 		}
-		if i&2 == 2 {
-			x = img.Rect.Dx() - 112
+		x, y := r.Min.X+2, r.Min.Y+12+4
+		if (r.Min.Y+r.Max.Y)/2 > hy {
+			y = r.Max.Y - 12*2 - 4
 		}
+		if (r.Min.X+r.Max.X)/2 > hx {
+			x = r.Max.X - 120
+		}
+
 		if state.Mans[i].Health > 0 {
 			h := int(state.Mans[i].Health * 110 / ManHealth)
 			draw.Draw(img, image.Rect(x, y-11, x+h, y-1), image.White, image.ZP, draw.Src)
-			draw.Draw(img, image.Rect(x+1, y-10, x+h-1, y-2), manfills[i], image.ZP, draw.Src)
+			draw.Draw(img, image.Rect(x+1, y-10, x+h-1, y-2), manfills[m.Man()], image.ZP, draw.Src)
+
+			if d, ok := m.(*DensityMan); ok {
+				// density gauge: filled up from the line for heavy, down
+				// for light.
+				g := image.Rect(x+112, y-11, x+118, y+12*2-1)
+				draw.Draw(img, g, image.White, image.ZP, draw.Src)
+				g = g.Inset(1)
+				zero := g.Max.Y - int(int64(g.Dy())*-Tuning.DensityMin/(Tuning.DensityMax-Tuning.DensityMin+1))
+				level := zero - int(int64(g.Dy())*d.Gravity_/(Tuning.DensityMax-Tuning.DensityMin+1))
+				if level < zero {
+					draw.Draw(img, image.Rect(g.Min.X, level, g.Max.X, zero), manfills[m.Man()], image.ZP, draw.Src)
+				} else {
+					draw.Draw(img, image.Rect(g.Min.X, zero, g.Max.X, level), offscreenfade, image.ZP, draw.Over)
+				}
+				draw.Draw(img, image.Rect(g.Min.X-1, zero, g.Max.X+1, zero+1), image.Black, image.ZP, draw.Src)
+			}
+
+			if n, ok := m.(*NormalMan); ok {
+				// shield cooldown: full when the bubble can be used again.
+				g := image.Rect(x+112, y-11, x+118, y+12*2-1)
+				draw.Draw(img, g, image.White, image.ZP, draw.Src)
+				g = g.Inset(1)
+				ready := g.Dy()
+				if n.Cooldown_ > state.Tick {
+					ready -= int(uint64(g.Dy()) * (n.Cooldown_ - state.Tick) / Tuning.ShieldCooldown)
+				}
+				draw.Draw(img, image.Rect(g.Min.X, g.Max.Y-ready, g.Max.X, g.Max.Y), manfills[m.Man()], image.ZP, draw.Src)
+			}
+
+			if v, ok := m.(*VacuumMan); ok {
+				// the held units are stacked with the next to be fired on
+				// top.
+				for j, h := range v.Held(state) {
+					top := y + 12*2 - 1 - (j+1)*8
+					b := image.Rect(x+112, top, x+118, top+7)
+					draw.Draw(img, b, image.White, image.ZP, draw.Src)
+					draw.Draw(img, b.Inset(1), image.NewUniform(h.Color(state, h)), image.ZP, draw.Src)
+				}
+				RenderText(img, fmt.Sprintf("%d lemons", v.Lemons_), image.Pt(x+60, y+12), color.White, c, false)
+			}
 		} else if m.Respawn() != 0 && m.Lives() > 0 {
-			RenderText(img, fmt.Sprintf("Respawn in %s", time.Duration(m.Respawn()-state.Tick)*time.Second/TicksPerSecond), image.Pt(x, y), color.White, ManData[i].Color, false)
+			RenderText(img, fmt.Sprintf("Respawn in %s", time.Duration(m.Respawn()-state.Tick)*time.Second/TicksPerSecond), image.Pt(x, y), color.White, c, false)
 		}
 		var lives string
-		if l := m.Lives(); l > 1 {
+		if Rules.Versus && i < len(state.Scores) {
+			lives = fmt.Sprintf("%d points", state.Scores[i].Points())
+		} else if l := m.Lives(); l > 1 {
 			lives = fmt.Sprintf("%d Mans", l)
 		} else if l == 1 {
 			lives = "1 Man!"
@@ -77,7 +132,7 @@ func Render(img *image.RGBA, me res.Man, state *State, err error) {
 		} else {
 			lives = fmt.Sprintf("%d Mans???", l)
 		}
-		RenderText(img, lives, image.Pt(x, y+12), color.White, ManData[i].Color, false)
+		RenderText(img, lives, image.Pt(x, y+12), color.White, c, false)
 		ping := "disconnected"
 		if p := m.Ping(); p > 0 {
 			if p > time.Millisecond {
@@ -85,23 +140,143 @@ func Render(img *image.RGBA, me res.Man, state *State, err error) {
 			}
 			ping = p.String()
 		}
-		RenderText(img, ping, image.Pt(x, y+12*2), color.White, ManData[i].Color, false)
+		RenderText(img, ping, image.Pt(x, y+12*2), color.White, c, false)
+	}
+
+	if Rules.Versus && state.RoundEnd != 0 {
+		if state.RoundOver() {
+			renderScoreboard(img, state)
+		} else {
+			renderTimer(img, state.RoundEnd-state.Tick)
+		}
+	} else if !Rules.Versus && state.TimeUp != 0 {
+		renderTimer(img, state.TimeUp-state.Tick)
+	}
+
+	if meta := &state.world.Meta; meta.Title != "" && time.Since(state.world.loaded) < TitleTime {
+		RenderText(img, meta.Title, image.Pt(hx, hy-12), color.White, color.Black, true)
+		if meta.Author != "" {
+			RenderText(img, "by "+meta.Author, image.Pt(hx, hy+4), color.White, color.Black, true)
+		}
 	}
 }
 
-func render(img *image.RGBA, me res.Man, state *State) {
-	img.Rect = img.Rect.Sub(img.Rect.Min)
+// renderTimer draws the time left at the top of img.
+func renderTimer(img *image.RGBA, ticks uint64) {
+	left := time.Duration(ticks) * time.Second / TicksPerSecond
+	RenderText(img, fmt.Sprintf("%d:%02d", left/time.Minute, left%time.Minute/time.Second), image.Pt((img.Rect.Min.X+img.Rect.Max.X)/2, img.Rect.Min.Y+12), color.White, color.Black, true)
+}
 
-	offX := int64(img.Rect.Dx()/2) - state.Mans[me].Position.X/PixelSize
-	offY := int64(img.Rect.Dy()/2) - state.Mans[me].Position.Y/PixelSize
+// renderStats draws every man's stats in the middle of img while the
+// scoreboard key is held.
+func renderStats(img *image.RGBA, state *State) {
+	tuningLock.RLock()
+	defer tuningLock.RUnlock()
+
+	draw.Draw(img, img.Rect, deadhaze, image.ZP, draw.Over)
+
+	hx, hy := (img.Rect.Min.X+img.Rect.Max.X)/2, (img.Rect.Min.Y+img.Rect.Max.Y)/2
+	y := hy - len(state.Stats)*14/2
 
+	RenderText(img, "man  dealt  taken  deaths  checkpoints  alive", image.Pt(hx, y-14), color.White, color.Black, true)
+	for i := range state.Stats {
+		if i >= len(state.Mans) {
+			break
+		}
+		m := state.Mans[i].UnitData.(Man)
+		s := &state.Stats[i]
+		var dealt, taken, deaths int64
+		for c := range s.Dealt {
+			dealt += s.Dealt[c]
+			taken += s.Taken[c]
+			deaths += s.Deaths[c]
+		}
+		alive := time.Duration(s.TimeAlive) * time.Second / TicksPerSecond
+		alive -= alive % time.Second
+		RenderText(img, fmt.Sprintf("%v Man (%d)  %s  %s  %d  %d  %v", m.Man(), i+1, humanize.Comma(dealt), humanize.Comma(taken), deaths, s.Checkpoints, alive), image.Pt(hx, y+i*14), color.White, ManData[m.Man()].Color, true)
+	}
+}
+
+// renderScoreboard draws the versus standings in the middle of img.
+func renderScoreboard(img *image.RGBA, state *State) {
+	hx, hy := (img.Rect.Min.X+img.Rect.Max.X)/2, (img.Rect.Min.Y+img.Rect.Max.Y)/2
+	standings := state.Standings()
+	y := hy - len(standings)*14/2
+
+	RenderText(img, "ROUND OVER", image.Pt(hx, y-14), color.White, color.Black, true)
+	for rank, i := range standings {
+		m := state.Mans[i].UnitData.(Man)
+		s := state.Scores[i]
+		RenderText(img, fmt.Sprintf("%d. %v Man (%d)  %d points  %d KO", rank+1, m.Man(), i+1, s.Points(), s.KnockOuts), image.Pt(hx, y+rank*14), color.White, ManData[m.Man()].Color, true)
+	}
+}
+
+// renderBackground draws the parallax layers behind the world into the part
+// r of img.
+func renderBackground(img *image.RGBA, r image.Rectangle, parallax []*image.RGBA, offX int64) {
+	dst := img.SubImage(r).(*image.RGBA)
 	for i, p := range parallax {
 		for x := img.Rect.Min.X - (int((-offX*int64(1+i)/int64(1+len(parallax)))%int64(p.Rect.Dx()))+p.Rect.Dx())%p.Rect.Dx(); x < img.Rect.Max.X; x += p.Rect.Dx() {
-			draw.Draw(img, image.Rect(x, img.Rect.Max.Y-p.Rect.Dy(), img.Rect.Max.X, img.Rect.Max.Y), p, p.Rect.Min, draw.Over)
+			draw.Draw(dst, image.Rect(x, img.Rect.Max.Y-p.Rect.Dy(), img.Rect.Max.X, img.Rect.Max.Y), p, p.Rect.Min, draw.Over)
 		}
 	}
+}
+
+// screenGrid divides r into cols×rows cells and returns cell i, filling each
+// column before moving on to the next.
+func screenGrid(r image.Rectangle, cols, rows, i int) image.Rectangle {
+	col, row := i/rows, i%rows
+	return image.Rect(
+		r.Min.X+r.Dx()*col/cols,
+		r.Min.Y+r.Dy()*row/rows,
+		r.Min.X+r.Dx()*(col+1)/cols,
+		r.Min.Y+r.Dy()*(row+1)/rows,
+	)
+}
+
+// splitScreen returns the part of r that shows man i when n mans share the
+// screen. Four mans get a corner each.
+func splitScreen(r image.Rectangle, n, i int) image.Rectangle {
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	rows := (n + cols - 1) / cols
+	return screenGrid(r, cols, rows, i)
+}
+
+func render(img *image.RGBA, me int, state *State) {
+	img.Rect = img.Rect.Sub(img.Rect.Min)
+
+	offX := int64(img.Rect.Dx()/2) - state.Mans[me].Position.X/PixelSize
+	offY := int64(img.Rect.Dy()/2) - state.Mans[me].Position.Y/PixelSize
+
+	renderBackground(img, img.Rect, state.world.Parallax(), offX)
 
 	state.world.Render(img, offX, offY)
+	renderEntities(img, state, offX, offY)
+
+	for c := range state.Broken {
+		r := image.Rect(int(c.X*TileSize+offX), int(c.Y*TileSize+offY), int(c.X*TileSize+TileSize+offX), int(c.Y*TileSize+TileSize+offY))
+		if r.Overlaps(img.Rect) {
+			draw.Draw(img, r, image.White, image.ZP, draw.Src)
+			renderBackground(img, r, state.world.Parallax(), offX)
+		}
+	}
+
+	for i := range state.Mans {
+		m, ok := state.Mans[i].UnitData.(*PortalMan)
+		if !ok {
+			continue
+		}
+		// a portal with nowhere to go is drawn faded
+		mask := fade[0]
+		if !m.Portals_[0].Placed() || !m.Portals_[1].Placed() {
+			mask = fade[VelocityClones/2]
+		}
+		for j, p := range m.Portals_ {
+			if p.Placed() {
+				draw.DrawMask(img, p.Rect().Add(image.Pt(int(offX), int(offY))), portalfills[j], image.ZP, mask, image.ZP, draw.Over)
+			}
+		}
+	}
 
 	for i := int64(VelocityClones); i >= 0; i-- {
 		state.EachUnit(func(u *Unit) {
@@ -152,10 +327,28 @@ func render(img *image.RGBA, me res.Man, state *State) {
 		})
 	}
 
-	{
-		u := &state.Mans[res.Man_Whip]
+	for i := range state.Mans {
+		u := &state.Mans[i]
+		m, ok := u.UnitData.(*NormalMan)
+		if !ok || !m.Shielding() || u.Health <= 0 {
+			continue
+		}
+		// the bubble gets fainter as it soaks up damage
+		mask := &bubble{
+			Center: image.Pt(int(u.Position.X/PixelSize+offX), int((u.Position.Y-u.Size(state, u).Y/2)/PixelSize+offY)),
+			Radius: int(Tuning.ShieldRadius / PixelSize),
+			Alpha:  uint8(32 + 64*m.ShieldLeft_/Tuning.ShieldAbsorb),
+		}
+		draw.DrawMask(img, mask.Bounds(), manfills[m.Man()], image.ZP, mask, mask.Bounds().Min, draw.Over)
+	}
+
+	for i := range state.Mans {
+		u := &state.Mans[i]
+		mm, ok := u.UnitData.(*WhipMan)
+		if !ok {
+			continue
+		}
 		r := u.Sprite(state, u).Rect
-		mm := u.UnitData.(*WhipMan)
 		if mm.WhipStart != 0 && mm.WhipStop == 0 {
 			velocity, whipEnd, _, collide, _ := mm.Whip(state, u)
 
@@ -213,6 +406,9 @@ func render(img *image.RGBA, me res.Man, state *State) {
 			gc.SetStrokeColor(color.Black)
 			gc.SetLineWidth(1)
 			gc.MoveTo(float64(u.Position.X/PixelSize+offX), float64(u.Position.Y/PixelSize-int64(r.Dy()/2)+offY))
+			for j := len(mm.WhipWraps) - 1; j >= 0; j-- {
+				gc.LineTo(float64(mm.WhipWraps[j].X/PixelSize+offX), float64(mm.WhipWraps[j].Y/PixelSize+offY))
+			}
 			gc.LineTo(float64(mm.WhipTether.X/PixelSize+offX), float64(mm.WhipTether.Y/PixelSize+offY))
 			gc.Stroke()
 		}
@@ -302,6 +498,29 @@ func RenderText(dst *image.RGBA, text string, p image.Point, fg, bg color.Color,
 	draw.DrawMask(dst, cache.Fg.Rect.Add(p), image.NewUniform(fg), image.ZP, cache.Fg, cache.Fg.Rect.Min, draw.Over)
 }
 
+// Terrain returns the tiles the world is drawn with.
+func (w *World) Terrain() []*image.RGBA {
+	if t, ok := tilesets[w.Meta.Tileset]; ok {
+		return t
+	}
+	return terrain
+}
+
+// Parallax returns the background layers of the world, back to front.
+func (w *World) Parallax() []*image.RGBA {
+	names := w.Meta.Parallax
+	if len(names) == 0 {
+		names = DefaultParallax
+	}
+	layers := make([]*image.RGBA, 0, len(names))
+	for _, name := range names {
+		if p, ok := parallaxes[name]; ok {
+			layers = append(layers, p)
+		}
+	}
+	return layers
+}
+
 var (
 	graphicsOnce  sync.Once
 	manspritessrc [res.Man_count][2]string = [...][2]string{
@@ -309,19 +528,111 @@ var (
 		res.Man_Density: {res.ManDensityPng, res.ManDensityCrouchPng},
 		res.Man_Vacuum:  {res.ManVacuumPng, res.ManVacuumCrouchPng},
 		res.Man_Normal:  {res.ManNormalPng, res.ManNormalCrouchPng},
+		res.Man_Portal:  {res.ManPortalPng, res.ManPortalCrouchPng},
 	}
 	mansprites    [res.Man_count][2]*image.RGBA
 	manfills      [res.Man_count]*image.Uniform
-	terrain       []*image.RGBA
+	portalfills   [2]*image.Uniform
+	liquidfill    *image.Uniform
+	fragilemask   *image.Alpha
+	terrain       []*image.RGBA // the default tileset
+	switchfill    *image.Uniform
 	tilemask      [1 << 10]*image.Alpha
+	tileside      *image.Gray
 	fade          [VelocityClones + 1]*image.Uniform
 	offscreenfade *image.Uniform
 	deadhaze      *image.Uniform
-	parallax      [2]*image.RGBA
+	tilesets      = make(map[string][]*image.RGBA)
+	parallaxes    = make(map[string]*image.RGBA)
 	lemonsprite   *image.RGBA
 	grubsprite    *image.RGBA
 )
 
+var (
+	shapedTilemaskCache = make(map[[2]int]*image.Alpha)
+	shapedTilemaskLock  sync.Mutex
+)
+
+// shapedTilemask clips tilemask[i] to the solid part of a tile with the given
+// shape and shades the edge along its surface.
+func shapedTilemask(i int, shape TileShape) *image.Alpha {
+	shapedTilemaskLock.Lock()
+	defer shapedTilemaskLock.Unlock()
+
+	if m, ok := shapedTilemaskCache[[2]int{i, int(shape)}]; ok {
+		return m
+	}
+
+	base := tilemask[i]
+	m := image.NewAlpha(base.Rect)
+	normal := shape.Normal()
+	scale := float64(TileSize) / math.Hypot(float64(normal.X), float64(normal.Y))
+
+	for x := 0; x < TileSize; x++ {
+		// surface height at the center of the column
+		top := float64(shape.Left()) + float64(shape.Right()-shape.Left())*(float64(x)+0.5)/TileSize
+		for y := int(top); y < TileSize; y++ {
+			a := base.Pix[base.PixOffset(x, y)]
+			if d := int((float64(y) + 0.5 - top) * scale); d < tileside.Rect.Dx() {
+				if g := tileside.Pix[tileside.PixOffset(d, TileSize/2)]; g < a {
+					a = g
+				}
+			}
+			m.Pix[m.PixOffset(x, y)] = a
+		}
+	}
+
+	shapedTilemaskCache[[2]int{i, int(shape)}] = m
+	return m
+}
+
+// bubble is a mask for a NormalMan's shield bubble, with a stronger rim.
+type bubble struct {
+	Center image.Point
+	Radius int
+	Alpha  uint8
+}
+
+func (b *bubble) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (b *bubble) Bounds() image.Rectangle {
+	return image.Rect(b.Center.X-b.Radius, b.Center.Y-b.Radius, b.Center.X+b.Radius, b.Center.Y+b.Radius)
+}
+
+func (b *bubble) At(x, y int) color.Color {
+	dx, dy := float64(x-b.Center.X)+0.5, float64(y-b.Center.Y)+0.5
+	d := math.Hypot(dx, dy)
+	if d > float64(b.Radius) {
+		return color.Alpha{}
+	}
+	if d > float64(b.Radius)-2 {
+		return color.Alpha{b.Alpha * 2}
+	}
+	return color.Alpha{b.Alpha}
+}
+
+// fragileCracks is drawn over fragile tiles.
+var fragileCracks = [TileSize]string{
+	"....#...........",
+	"....#...........",
+	".....#..........",
+	".....#..........",
+	"......#.........",
+	"......##........",
+	".....#..##......",
+	".....#....##....",
+	"....#.......#...",
+	"....#........##.",
+	".....#..........",
+	"......#.........",
+	"......#.........",
+	".....#..........",
+	".....#..........",
+	"....#...........",
+}
+
 func readRGBA(s string) *image.RGBA {
 	src, err := png.Decode(strings.NewReader(s))
 	if err != nil {
@@ -332,6 +643,22 @@ func readRGBA(s string) *image.RGBA {
 	return dst
 }
 
+// tuningLock is held by rendering while it reads ManData and the images made
+// from it, so a tuning from the server can replace them in between frames.
+var tuningLock sync.RWMutex
+
+// graphicsTuning updates the cached images that depend on ManData. Once the
+// game has started, tuningLock must be held.
+func graphicsTuning() {
+	for i, d := range ManData {
+		manfills[i] = image.NewUniform(d.Color)
+	}
+
+	c := ManData[res.Man_Portal].Color
+	portalfills[0] = image.NewUniform(c)
+	portalfills[1] = image.NewUniform(color.RGBA{c.R/2 + 128, c.G/2 + 128, c.B/2 + 128, 255})
+}
+
 func graphicsInit() {
 	graphicsOnce.Do(func() {
 		font, err := truetype.Parse([]byte(res.LuxisrTtf))
@@ -353,23 +680,27 @@ func graphicsInit() {
 			if mansprites[i][1].Rect.Dx() != int(d.SizeCrouch.X/PixelSize) || mansprites[i][1].Rect.Dy() != int(d.SizeCrouch.Y/PixelSize) {
 				log.Panicln("man sprite crouch size mismatch", res.Man(i))
 			}
-			manfills[i] = image.NewUniform(d.Color)
 		}
+		graphicsTuning()
 
-		dst := readRGBA(res.TerrainPng)
-		if dst.Rect.Dx()%TileSize != 0 || dst.Rect.Dy() != TileSize {
-			log.Panic("tile size mismatch")
-		}
-		for x := dst.Rect.Min.X; x < dst.Rect.Max.X; x += TileSize {
-			terrain = append(terrain, dst.SubImage(image.Rect(x, dst.Rect.Min.Y, x+TileSize, dst.Rect.Max.Y)).(*image.RGBA))
+		for name, src := range tilesetsrc {
+			dst := readRGBA(src)
+			if dst.Rect.Dx()%TileSize != 0 || dst.Rect.Dy() != TileSize {
+				log.Panicln("tile size mismatch", name)
+			}
+			for x := dst.Rect.Min.X; x < dst.Rect.Max.X; x += TileSize {
+				tilesets[name] = append(tilesets[name], dst.SubImage(image.Rect(x, dst.Rect.Min.Y, x+TileSize, dst.Rect.Max.Y)).(*image.RGBA))
+			}
 		}
+		terrain = tilesets[DefaultTileset]
 
-		dst = readRGBA(res.TileSidePng)
+		dst := readRGBA(res.TileSidePng)
 		tileSide := image.NewGray(dst.Rect)
 		draw.Draw(tileSide, tileSide.Rect, dst, tileSide.Rect.Min, draw.Src)
 		if tileSide.Rect.Dy() != TileSize {
 			log.Panic("tile size mismatch")
 		}
+		tileside = tileSide
 		dst = readRGBA(res.TileCornerInnerPng)
 		tileCornerInner := image.NewGray(dst.Rect)
 		draw.Draw(tileCornerInner, tileCornerInner.Rect, dst, tileCornerInner.Rect.Min, draw.Src)
@@ -443,8 +774,19 @@ func graphicsInit() {
 
 		offscreenfade = image.NewUniform(color.Alpha{0x40})
 		deadhaze = image.NewUniform(color.RGBA{64, 64, 64, 64})
-		parallax[0] = readRGBA(res.Parallax0Png)
-		parallax[1] = readRGBA(res.Parallax1Png)
+		liquidfill = image.NewUniform(color.RGBA{16, 48, 112, 112})
+		switchfill = image.NewUniform(color.RGBA{192, 0, 0, 255})
+		fragilemask = image.NewAlpha(image.Rect(0, 0, TileSize, TileSize))
+		for y, row := range fragileCracks {
+			for x, c := range row {
+				if c == '#' {
+					fragilemask.Pix[fragilemask.PixOffset(x, y)] = 0x80
+				}
+			}
+		}
+		for name, src := range parallaxsrc {
+			parallaxes[name] = readRGBA(src)
+		}
 		lemonsprite = readRGBA(res.LemonPng)
 		grubsprite = readRGBA(res.GrubPng)
 	})
@@ -1,3 +1,3 @@
 package res
 
-const FooLevel = "0\xff\x81\x03\x01\x01\x05World\x01\xff\x82\x00\x01\x03\x01\x03Min\x01\xff\x84\x00\x01\x03Max\x01\xff\x84\x00\x01\x05Tiles\x01\xff\x88\x00\x00\x00\x1f\xff\x83\x03\x01\x01\x05Coord\x01\xff\x84\x00\x01\x02\x01\x01X\x01\x04\x00\x01\x01Y\x01\x04\x00\x00\x00\x1f\xff\x87\x02\x01\x01\x10[]main.WorldTile\x01\xff\x88\x00\x01\xff\x86\x00\x00:\xff\x85\x03\x01\x01\tWorldTile\x01\xff\x86\x00\x01\x03\x01\x04Tile\x01\x04\x00\x01\x05Solid\x01\x02\x00\x01\vSpecialTile\x01\x04\x00\x00\x00\xfe\x1c\x87\xff\x82\x01\x01#\x01#\x00\x01\x01\xfe\x01\x1a\x01\x04\x00\x01\xfe\r \x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\n\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\f\x01\x01\x01\x04\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x0e\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\n\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\f\x01\x01\x01\x04\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\x0e\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\x06\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x04\x01\x01\x01\x02\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x06\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x01\b\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\n\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\f\x01\x01\x01\x04\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\x0e\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00\x01\b\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x01\x02\x01\x01\x00\x00"
+const FooLevel = "{\n\t\"Version\": 1,\n\t\"Min\": {\n\t\t\"X\": -18,\n\t\t\"Y\": -18\n\t},\n\t\"Max\": {\n\t\t\"X\": 141,\n\t\t\"Y\": 2\n\t},\n\t\"Legend\": {\n\t\t\"#\": {\n\t\t\t\"Tile\": 1,\n\t\t\t\"Solid\": true\n\t\t},\n\t\t\"A\": {\n\t\t\t\"Tile\": 4,\n\t\t\t\"Solid\": true\n\t\t},\n\t\t\"B\": {\n\t\t\t\"Tile\": 3,\n\t\t\t\"Solid\": true\n\t\t},\n\t\t\"C\": {\n\t\t\t\"Tile\": 5,\n\t\t\t\"Solid\": true\n\t\t},\n\t\t\"D\": {\n\t\t\t\"Tile\": 6,\n\t\t\t\"Solid\": true,\n\t\t\t\"Special\": \"checkpoint\"\n\t\t},\n\t\t\"E\": {\n\t\t\t\"Tile\": 7,\n\t\t\t\"Solid\": true\n\t\t},\n\t\t\"F\": {\n\t\t\t\"Tile\": 2,\n\t\t\t\"Solid\": true,\n\t\t\t\"Special\": \"bounce\"\n\t\t}\n\t},\n\t\"Rows\": [\n\t\t\"#A..............................................................................................................................................................\",\n\t\t\"#A........................................................................BAAAAAAAAB.................................................................BAAAAAACDEA\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.......................................................BFFFB.....A##########\",\n\t\t\"#A................................................................BCDEB...A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A........................................................................A########A.................................................................A##########\",\n\t\t\"#A..........................................BAAAAAB.......................A########A...............................................BFFFB.............A##########\",\n\t\t\"#A..........................................A#####A....BAAAAAAAAAAAAAAAAAFB########A.................................................................A##########\",\n\t\t\"#A..........................................A#####A....A###########################A.................................................................A##########\",\n\t\t\"#A..................................FBAAAAAAB#####A....A###########################A....BFFFB....BAAAAAAAAAAAAB......................................A##########\",\n\t\t\"#A.................................FB#############A....A###########################A....A###A....A############A......................................A##########\",\n\t\t\"#A................................FB##############A....A###########################A....A###A....A############BAAAAB.................................A##########\",\n\t\t\"#A.......................BAAAAAAAAB###############A....A###########################A....A###A....A#################A.................................A##########\",\n\t\t\"#BAAAAAAAAAAAAAACDEAAAAAAB########################A....A###########################A....A###A....A#################BAAAAB..BFFFB.....................A##########\",\n\t\t\"##################################################A....A###########################A....A###A....A######################A............................A##########\"\n\t]\n}\n"
@@ -0,0 +1,3 @@
+package res
+
+const ManPortalPng = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x1e\x00\x00\x00.\b\x02\x00\x00\x00\xb0(>X\x00\x00\x04\xa3IDATx\x9c\xec\x96{h\x1c\xd5\x17\xc7Ͻ\xf3ڝ}dv\xbb\x9b_\x9a\a\xbf%A\x1b\x1aڄ$Vm\xa3\xf5\x9f`\x95R\x1a!Xb\xb1X\xc5H\xfd#\x01K\xaa\"\b\x85BE\x8a]\xadR+\"\x14\t\xb4\x06\xa4\xb6\xb5P\x82\xafh\x8bM\xa3\x9b\b\xa1\xb4i\xb2\xad\xa9MRw\x93}t\xb2\xf3\xbc2ٽIv\x9bҭI\xc1?\xf2\x9d\xf9\xe3\x9e3\xf7~f\xf7̹\xe7\x1ev\xab\x14\x84\a#\x16\x00j\x02\xd5\xd4\\2\x85\xc2\xfd\x98\x8e\x97^\xcb\xe8\x1c4\x9b\x83&\x84\x100\xa9u7!\x04\b!D\xcd\xfcЪ9\xad\xf3\t\x87G\xa0\x8el\x11\xd0USS\fM1t\xc5\xc0\xc0`\xc4\xf2\x8c]\xc0\xe2\x9do\xcaE\x03@\xed\xb3\x81m{\xd7Q\xeb\xaeJF\x95\xf1\xe1\xf8og\xae]\xf8zXQn\xbb8\x1fF\xf8\x1e\xe8<\xe5\xf4\nN\xaf\xbf\xa2\u07bf\xbe\xb9\xe2p\xeb\x0frd\xca\xc9y\xf3E\xcb1\xf5ػ\x17\xa8e]\x82\x83\xb598\x87G\bT\xfb\xcak\xfd\x9c\x8d\x01\x80\x92JO\xf3;\xf5\x9f\xb7\xf5\x18\xa6\xc6`./\xb4\xa6\x18\xfdgG\x1d\xacD\xe3L\xac\x9b\x18:Qu\xf3\x0f\x97\xcf\xf6ʡ'\xff\xbfv\x05\x00\xacm,+~X\x8a\rk\fp\xf9&\x1f\x02\xc43\xf6\xf4-0\xa2\x8dq\xd8Y\xb7\x8b\xf3\x15\xf0Er\xc48\xbe\xb7\x97N\x84@\xb5\xcf$\xfa\x12\xe45FXd\xa5\xd1\xc1\xa8\xa6\x183\x0e\xf0\x96:\xe9\xc3š\xad\x953\xf9\xa0\xabt\x13\x10\x92\x19,\x1e\xad\x99\x8aT$\xda]\x99\xe0\xfe}=I\x9f,\x0em\x10]\xd6c\x95\x1bVR\aL\x84\xe3\x18\xb1\x8bB\x13bN뉸z\xcb\x1fp>\xf7V]\x1a}\xe3\xd2\xe4\xc8\ufdd8lt\x96q\xe7E\xc0LjQk\x04`\x12ú\xc1\x10\v\x84\xa7_\xa8ڸ}\x95 f\x96\x7fs \x84\x81c1\x9f/Z,\xe0_\n6P\xcb*H\x05~\xbbT$\xba\xfd6\x843\x15\xc3\xd0̮}\x17/\xfdr\xd3\xc5\xf9\xe8\xc4<М\xc0T7\x96Q+W\xc4$C\xbd\x13'?\xe8\xbf6\x10q\xb0\x12\x87\x85\xfb@\x9b:\x19\xbb\x1a\xa3\x16\x10\x93\xc8q5\x11I%\"\xa9\xf1\xe1\xf8@\xf7\x9f\x89H\x8aÂ\x9b\xf3\xb3\xf3\xf6w^\xe8D4\xf5^ә\x9c\x8f\x83\xadX0\x18a\x06\xd9$ޓS\xed\xf2E[ \xc0\x05|!\xb5\xeeO\x18\xfeݺe\xf42z\xd1\xe8\x05\xf2\xfa|\xd7\xd0\xf9\xae!\xfaff\xc9\xd0\x02#\n\x8cH\xad\xffj@\x1ep\xacuSM\xea\x99zo\xd5e@\x18\xb1N\xd6{φq\xfe\x95\xd0\"t\b.n\xc5\x1c:P\xe3}\xf1\xfd\r\xd6k4cb$~\xb4\xe3\xdcd\xf2/\xafPB'\xcfI1d\xcdT2\xbd\x19\xe7\xa1n \xc4|\xf5\xd3'\x00\xe0HkO\xd6g,\xaf\xf3{\x8a\xc5/\xda\x7f\x96\x8aĦ7k\xf7\xff\xda\xdc^\xd5\x19UnЅ\x80\x00\xcf6Ǐ6\x95\xafz\xbc\xe8hǹ\xa8\"\xe7\xb4m\xb9\x01\x99U\xe8\xecu\x00`X\xbcew\r\xcb3\aB\xcfϸፚc\r\xdb\x1e\xda\xf4\xfa\x1a\xbb\x8bk[\xddٲ\xef\xb1tw\xd0w:\xfc\xf6\xe9\xcd\xe9\ue83d\xaa\x93b\x16\xfa\x8c\x9bv\xadپ\x7f\xfd\x96\xdd5=\x9dWv\x1e\xb4NŶ\xd5ւ\x9d\a\x1bV\x94:\xed.\xae\xa3\xfe8\x00\xfc\xf4\xe5eyJ\xfd\xf6\xd0\xc0Ը\xbcg\xddW\xe99%\x95Ym\xeaܯ&3\xff\xb5\xa2\xce\x1f\x19\xbd\xfdю\xee\xa1މ\xe0`Kߩ0\x00\xf4\x9d\n\xd7m\x0e\xf4t^\xd6R\x86\"\xeb6&Ӏ!\xc06\x91\xdfs\xe2\x99TR\xb3\x9aX\x91\xcd\x02Ϣ\xd3\xc7\xd0\xc7/\x7f\xc7a\x01\xcf8o^\x89\x95\xd7Z\xe7Ky]\xe1\xf8\xd58\xc5Y9c\xe8&\xcb[\xa1\x7f\xed\xb3\xa7Ɔb\x1f\xee\xe8\x0e\x0e\xb6 \x06\x11 \xe9\t\v絋\xf398\x89A\xdc\xe1\xd6\xef=\xc5bp\xb0ųR<\xb2\xebG\x98\xb7\xa6\xf7\xc4\b/\xb2Սe\x17O\x86+\x1e)\f\x0e\xb6\x00@\xeb'\x1b\xe9\xf3\x8c\xd0V)XYZ!\x1b\x99\x93;\x9dp&1\xa6\xd41\x00\x90\xfe'N\x8d˳\xbf\x17\x00ܜ?\xa6M\b\"k\xe8DW\r\x9b\x83S\xa7u\xd3$\xa2\x9b\x9f\x8e\xeb\xe93\xda\xcd\xfbC\xe1~kdc\x9d66\xab\x85ň\xf1\n%&1`\x12$ލQV\x91\xf2\xf0Ő\xee\xa4y\x00\r\xec\x16\x03 \x05\x02\x0f\xf3w\x99\xe5\x0e\x85\xfb\xa9\xb9\x94\xfag\x00\x04\xfa\x9d#\xd2?\x1b\x1e\x00\x00\x00\x00IEND\xaeB`\x82"
@@ -0,0 +1,3 @@
+package res
+
+const ManPortalCrouchPng = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x1e\x00\x00\x00\x1e\b\x02\x00\x00\x00\xb4R9\xf5\x00\x00\x02{IDATx\x9c\xec\x94\xcdk\x13A\x18\x87ߙ\xd9\xd9Iv\x93f\x13\xb3R?\x8a\xc1\x82\x16\x84Fj\U000608a7\"xR\xa1 \xe8E\x10<z\x16\x04\xa1\xe0\xc1\x83H\xc1\x83\x17\xff\x00\xa5 \xe8\xa1 \x1e\x04\x91B+\xdaT\x90\x1ejl\xb5\xa2mM4IM\xb3\x1f3#k3h\xd2\x16\xb7&\xde\xfa{\xe7\xb0\xcf\xecγ\xf0\xee\xceh\xa7\xada\xf8?\xd1\x00\xe0`&\xab\xb0m\x99\x9c\xcdiMSRJ\tB\xd1FA\b\x10BH\xe1\xfaiV\xbbb\xc5\xd7+f\x92\xa9\x89\xc6H\xf0]\xe19\xdcs\xb8\xefp\f\x04#M'Q\x86\x8d\xb5ojV\x03@ߩ̹\xa1Ê6\xccr\xd1Yȗ_\x8d\u038d?\xcc;Ώ8Mc\x84\xff\xa2\x0eY\xb1\x14\x8b\xa5\xec\xee~\xfb\xc8`\xf7\xdd\xcbϪ\x85\xef1\x9a\n\xab\xae\x96\xdc\xfb\xd7\xc7\x15\x05\xc5L-bR3\xc92\xd9\xf4\xde>\x9bF\b\x00\xec\xeaI\x0e^\xeb\xbfw\xe59\x17\x1e\xc14\x94\xdasx\xeeɼ\xa9Y\xaa\xcf2\x18\x92\xfb\xd2\xf5śx:r\xe9\xce\xf1=\xbd\xdb\x00\xa0w\xa0k\xe7>\xab\x94\xf7\b\xfcV7tgm!@:\x89\xae\x0eF\x8c\b1\xa3ZG\x9c\xa6\x13zg\xb5\xc0\x1f\fM\xa8\a!\x93M\v\xe9+\n\xa1ި0\u0086fͿ-z\x0e\x0f\x18 \xb5;\xa6n\xb6\xa6\x0eV\xfe\xfa\x1f|Wm\x02)\xeb\x17\xad\xab=\xe1X\x9dF4^o\xee\xd7\x0f\xcb\xeaNkj.\xfd\xaa_\xea9\xbaCM\xc0\xe2l\x19#\xad%\xb5\x94bů\x94\xdd%;\x13;{\xf5Ъ\xfa\xd3\xf4\xb7\xf7\xaf\x97H\xa3\xba\x01֖\x04\xb1\xec\x15\x83+\x00!y0\x80\x1b\tv\xf2\xfc\x81\x13\x17\xf63\xa3\xbe\xfcѭI\fT\xc3zX\xb5\x91\xd0/\x0e\x1fS\x14\x1cH\t;ju\x1a\x1dv\x04\xe1\xfa\x89\xc1=1r\xe3\xe5\xf4\x8b\xcfq\x9aV\x0f\x86PSF\xb2\x03]\x8a\x9a#\x85\x9c\x99X||;77U05\x8bb\xb6\t\xb5\xf0\xe5\x97w%E \x85\xac\x96\xddJ\xa1V)\xd4\x16\xf2婧\x1f+\x85\x1aŬ\x83\xda\xda\x1f\xfb;\x94\xbaR\xac\xdd<3\xda\xf4qp\xd0\v\x82\x11&(b\xe9ɦ\xd3.\xac:\x10\x01N\xe8\xdb\x15m.\x18\xfemݖzKݲz\x9d\xffzldfldF\xbd\x99\xb4M͈\xc1\x88\xa1\xa8\xa5\x04\xea\xc9ٜ\xc2v\xe6\xe7\x00H;ї\xbd\x8a\xa2\x84\x00\x00\x00\x00IEND\xaeB`\x82"
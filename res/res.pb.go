@@ -23,29 +23,44 @@ var _ = math.Inf
 type Type int32
 
 const (
-	Type_Ping      Type = 0
-	Type_SelectMan Type = 1
-	Type_Input     Type = 2
-	Type_StateDiff Type = 3
-	Type_FullState Type = 4
-	Type_World     Type = 5
+	Type_Ping        Type = 0
+	Type_SelectMan   Type = 1
+	Type_Input       Type = 2
+	Type_StateDiff   Type = 3
+	Type_FullState   Type = 4
+	Type_World       Type = 5
+	Type_Tuning      Type = 6
+	Type_EditTile    Type = 7
+	Type_EditObjects Type = 8
+	Type_EditCursor  Type = 9
+	Type_EditSave    Type = 10
 )
 
 var Type_name = map[int32]string{
-	0: "Ping",
-	1: "SelectMan",
-	2: "Input",
-	3: "StateDiff",
-	4: "FullState",
-	5: "World",
+	0:  "Ping",
+	1:  "SelectMan",
+	2:  "Input",
+	3:  "StateDiff",
+	4:  "FullState",
+	5:  "World",
+	6:  "Tuning",
+	7:  "EditTile",
+	8:  "EditObjects",
+	9:  "EditCursor",
+	10: "EditSave",
 }
 var Type_value = map[string]int32{
-	"Ping":      0,
-	"SelectMan": 1,
-	"Input":     2,
-	"StateDiff": 3,
-	"FullState": 4,
-	"World":     5,
+	"Ping":        0,
+	"SelectMan":   1,
+	"Input":       2,
+	"StateDiff":   3,
+	"FullState":   4,
+	"World":       5,
+	"Tuning":      6,
+	"EditTile":    7,
+	"EditObjects": 8,
+	"EditCursor":  9,
+	"EditSave":    10,
 }
 
 func (x Type) Enum() *Type {
@@ -72,7 +87,8 @@ const (
 	Man_Density Man = 1
 	Man_Vacuum  Man = 2
 	Man_Normal  Man = 3
-	Man_count   Man = 4
+	Man_Portal  Man = 4
+	Man_count   Man = 5
 )
 
 var Man_name = map[int32]string{
@@ -80,14 +96,16 @@ var Man_name = map[int32]string{
 	1: "Density",
 	2: "Vacuum",
 	3: "Normal",
-	4: "count",
+	4: "Portal",
+	5: "count",
 }
 var Man_value = map[string]int32{
 	"Whip":    0,
 	"Density": 1,
 	"Vacuum":  2,
 	"Normal":  3,
-	"count":   4,
+	"Portal":  4,
+	"count":   5,
 }
 
 func (x Man) Enum() *Man {
@@ -147,6 +165,7 @@ type Packet struct {
 	Y                *int64  `protobuf:"zigzag64,4,opt,name=y" json:"y,omitempty"`
 	Data             []byte  `protobuf:"bytes,5,opt,name=data" json:"data,omitempty"`
 	Tick             *uint64 `protobuf:"varint,6,opt,name=tick" json:"tick,omitempty"`
+	Slot             *uint32 `protobuf:"varint,7,opt,name=slot" json:"slot,omitempty"`
 	Mouse1           *Button `protobuf:"varint,16,opt,name=mouse1,enum=Button" json:"mouse1,omitempty"`
 	Mouse2           *Button `protobuf:"varint,17,opt,name=mouse2,enum=Button" json:"mouse2,omitempty"`
 	KeyUp            *Button `protobuf:"varint,18,opt,name=key_up,enum=Button" json:"key_up,omitempty"`
@@ -202,6 +221,13 @@ func (m *Packet) GetTick() uint64 {
 	return 0
 }
 
+func (m *Packet) GetSlot() uint32 {
+	if m != nil && m.Slot != nil {
+		return *m.Slot
+	}
+	return 0
+}
+
 func (m *Packet) GetMouse1() Button {
 	if m != nil && m.Mouse1 != nil {
 		return *m.Mouse1
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// DamageCause says what hurt a unit.
+type DamageCause int
+
+const (
+	DamageOther      DamageCause = iota
+	DamageFall                   // hitting the world too fast
+	DamageCollision              // running into another unit
+	DamageWhip                   // a WhipMan's whip
+	DamageVacuum                 // holding units in a vacuum
+	DamagePound                  // a DensityMan's ground pound or cannon shot
+	DamageCrush                  // being landed on or pushed into something from below
+	DamageOutOfWorld             // leaving the level
+	DamageTime                   // running out of time
+	DamageCause_count
+)
+
+var damageCause_names [DamageCause_count]string = [...]string{
+	DamageOther:      "other",
+	DamageFall:       "fall",
+	DamageCollision:  "collision",
+	DamageWhip:       "whip",
+	DamageVacuum:     "vacuum",
+	DamagePound:      "pound",
+	DamageCrush:      "crush",
+	DamageOutOfWorld: "out of world",
+	DamageTime:       "time",
+}
+
+func (c DamageCause) String() string {
+	if c < 0 || c >= DamageCause_count {
+		return fmt.Sprintf("DamageCause(%d)", int(c))
+	}
+	return damageCause_names[c]
+}
+
+// rules are the server's game settings. They are sent to clients along with
+// the tuning. Scales are percentages and times are in ticks.
+type rules struct {
+	FriendlyFire    int64 // damage mans deal to other mans with their powers: 0 is off, 100 is on
+	FallDamage      int64
+	CollisionDamage int64
+	ManKills        bool // whether a man's damage can finish off another man
+
+	Versus         bool   // mans play against each other for points
+	RoundTime      uint64 // length of a versus round on levels without a time limit
+	RoundBreak     uint64 // time the scores are shown before the next round
+	KnockOutScore  int64  // points for each knock-out
+	DamageScore    int64  // damage dealt per point
+	KnockOutCredit uint64 // how long after a hit the man who hit is blamed for more damage
+}
+
+var Rules = rules{
+	FriendlyFire:    0,
+	FallDamage:      100,
+	CollisionDamage: 100,
+	ManKills:        true,
+
+	RoundTime:      3 * 60 * TicksPerSecond,
+	RoundBreak:     10 * TicksPerSecond,
+	KnockOutScore:  10,
+	DamageScore:    1000,
+	KnockOutCredit: 5 * TicksPerSecond,
+}
+
+// LoadRules reads a JSON rules file. Anything not in the file keeps its
+// current value.
+func LoadRules(r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	rl := Rules
+	err = json.Unmarshal(b, &rl)
+	if err != nil {
+		return err
+	}
+
+	err = checkRules(&rl)
+	if err != nil {
+		return err
+	}
+
+	Rules = rl
+	return nil
+}
+
+func checkRules(r *rules) error {
+	if r.FriendlyFire < 0 || r.FallDamage < 0 || r.CollisionDamage < 0 {
+		return fmt.Errorf("rules: damage scales cannot be negative")
+	}
+	if r.RoundTime == 0 || r.DamageScore <= 0 || r.KnockOutScore < 0 {
+		return fmt.Errorf("rules: RoundTime and DamageScore must be positive and KnockOutScore cannot be negative")
+	}
+	return nil
+}
+
+// Damage applies the rules to amount damage dealt to u by by.
+func (r *rules) Damage(u, by *Unit, amount int64, cause DamageCause) int64 {
+	switch cause {
+	case DamageFall:
+		amount = amount * r.FallDamage / 100
+	case DamageCollision, DamageCrush:
+		amount = amount * r.CollisionDamage / 100
+	}
+
+	// holding a teammate strains a VacuumMan whatever the rules say, and
+	// running into each other is not friendly fire.
+	if by != nil && by != u && by.IsMan() && u.IsMan() && cause != DamageVacuum {
+		if !r.Versus && cause != DamageCollision && cause != DamageCrush {
+			amount = amount * r.FriendlyFire / 100
+		}
+		if !r.ManKills && amount >= u.Health {
+			amount = u.Health - 1
+		}
+	}
+
+	return amount
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/Rnoadm/wdvn/res"
 	"log"
 	"net"
-	"sync/atomic"
 	"time"
 )
 
@@ -21,19 +20,23 @@ func Listen(l net.Listener, world *World) {
 		input      = make(chan *res.Packet)
 		state      = make(chan (<-chan []byte))
 		connection = make(chan bool)
+		roster     = make(chan rosterRequest)
 		accept     = make(chan net.Conn)
-		connected  [res.Man_count]uint64
 	)
 	defer close(register)
 	quitWait.Add(3)
 	go Multicast(broadcast, register, unregister)
-	go Manager(input, state, connection, broadcast, world)
+	go Manager(input, state, connection, roster, broadcast, world)
 	go Accept(accept, l)
 
 	worldPacket := &res.Packet{
 		Type: Type_World,
 		Data: Encode(world),
 	}
+	tuningPacket := &res.Packet{
+		Type: Type_Tuning,
+		Data: EncodeTuning(),
+	}
 
 	for {
 		select {
@@ -43,7 +46,7 @@ func Listen(l net.Listener, world *World) {
 			connection <- true
 
 			quitWait.Add(1)
-			go Serve(conn, ch, broadcast, state, worldPacket, input, &connected, func() {
+			go Serve(conn, ch, broadcast, state, tuningPacket, worldPacket, input, roster, func() {
 				go func() {
 					for _ = range ch {
 						// discard
@@ -109,7 +112,23 @@ func Multicast(broadcast <-chan *res.Packet, register, unregister <-chan chan<-
 	}
 }
 
-func Serve(conn net.Conn, in <-chan *res.Packet, out chan<- *res.Packet, state <-chan <-chan []byte, world *res.Packet, input chan<- *res.Packet, connected *[res.Man_count]uint64, disconnect func()) {
+// rosterRequest asks the Manager for a man to control. Slot is the man the
+// connection controls now, or -1 if it has just connected. Man is the kind of
+// man wanted, or res.Man_count for any. If Leave is set, the connection is
+// giving up its man and no reply is sent.
+type rosterRequest struct {
+	Slot  int
+	Man   res.Man
+	Leave bool
+	Reply chan<- rosterReply
+}
+
+type rosterReply struct {
+	Slot int
+	Man  res.Man
+}
+
+func Serve(conn net.Conn, in <-chan *res.Packet, out chan<- *res.Packet, state <-chan <-chan []byte, tuning, world *res.Packet, input chan<- *res.Packet, roster chan<- rosterRequest, disconnect func()) {
 	defer disconnect()
 	defer conn.Close()
 
@@ -118,41 +137,41 @@ func Serve(conn net.Conn, in <-chan *res.Packet, out chan<- *res.Packet, state <
 	go Read(conn, read, errors)
 	go Write(conn, write, errors)
 
-	var man res.Man
-	// check for an empty slot
-	for man = 0; man < res.Man_count; man++ {
-		if atomic.CompareAndSwapUint64(&(*connected)[man], 0, 1) {
-			break
+	reply := make(chan rosterReply, 1)
+	join := func(slot int, man res.Man) (rosterReply, bool) {
+		select {
+		case roster <- rosterRequest{Slot: slot, Man: man, Reply: reply}:
+			return <-reply, true
+		case <-quitRequest:
+			return rosterReply{}, false
 		}
 	}
-	// multiple people control normal man as a last resort
-	if man == res.Man_count {
-		man = res.Man_Normal
-		atomic.AddUint64(&(*connected)[man], 1)
+
+	r, ok := join(-1, res.Man_count)
+	if !ok {
+		return
 	}
-	pman := man.Enum()
+	slot, man := r.Slot, r.Man
 	// leave the character when we disconnect
 	defer func() {
-		release := &res.Packet{Type: Type_Input}
-		proto.Merge(release, ReleaseAll)
-		release.Man = pman
-
-		go Send(input, release)
-
-		atomic.AddUint64(&(*connected)[man], ^uint64(0))
-
+		select {
+		case roster <- rosterRequest{Slot: slot, Leave: true}:
+		case <-quitRequest:
+		}
 	}()
 
-	inputCache := new(res.Packet)
-
-	log.Println(conn.RemoteAddr(), "connected for", man)
+	log.Println(conn.RemoteAddr(), "connected for", man, "in slot", slot)
 
 	// tell the client which man they are
 	write <- &res.Packet{
 		Type: Type_SelectMan,
-		Man:  pman,
+		Man:  man.Enum(),
+		Slot: proto.Uint32(uint32(slot)),
 	}
 
+	// send the tuning values so the client agrees with us
+	write <- tuning
+
 	// send the world
 	write <- world
 
@@ -191,38 +210,39 @@ func Serve(conn net.Conn, in <-chan *res.Packet, out chan<- *res.Packet, state <
 				if since <= 0 {
 					since = time.Nanosecond
 				}
-				inputCache.Tick = proto.Uint64(uint64(since))
 				go Send(input, &res.Packet{
 					Type: Type_Input,
-					Man:  pman,
-					Tick: inputCache.Tick,
+					Slot: proto.Uint32(uint32(slot)),
+					Tick: proto.Uint64(uint64(since)),
 				})
 				lastPing = time.Now()
 
 			case res.Type_SelectMan:
-				if atomic.CompareAndSwapUint64(&(*connected)[p.GetMan()], 0, 1) {
-					log.Println(conn.RemoteAddr(), "switched from", man, "to", p.GetMan())
-
-					release, press := &res.Packet{Type: Type_Input}, &res.Packet{Type: Type_Input}
-					proto.Merge(release, ReleaseAll)
-					proto.Merge(press, inputCache)
-					release.Man, press.Man = pman, p.Man
-
-					go Send(input, release)
-					go Send(input, press)
+				if p.GetMan() < 0 || p.GetMan() >= res.Man_count {
+					break
+				}
+				r, ok := join(slot, p.GetMan())
+				if !ok {
+					return
+				}
+				if r.Slot != slot || r.Man != man {
+					log.Println(conn.RemoteAddr(), "switched from", man, "in slot", slot, "to", r.Man, "in slot", r.Slot)
 
-					atomic.AddUint64(&(*connected)[man], ^uint64(0))
-					man, pman = p.GetMan(), p.Man
+					slot, man = r.Slot, r.Man
 
-					go Send(write, p)
+					go Send(write, &res.Packet{
+						Type: Type_SelectMan,
+						Man:  man.Enum(),
+						Slot: proto.Uint32(uint32(slot)),
+					})
 				}
 
 			case res.Type_Input:
-				p.Man = pman
+				p.Man = nil
+				p.Slot = proto.Uint32(uint32(slot))
 				p.Data = nil
 				p.Tick = nil
 				go Send(input, p)
-				proto.Merge(inputCache, p)
 
 			case res.Type_FullState:
 				log.Println(conn.RemoteAddr(), "requested full state update")
@@ -255,12 +275,13 @@ func Serve(conn net.Conn, in <-chan *res.Packet, out chan<- *res.Packet, state <
 	}
 }
 
-func Manager(in <-chan *res.Packet, out chan<- <-chan []byte, connection <-chan bool, broadcast chan<- *res.Packet, world *World) {
+func Manager(in <-chan *res.Packet, out chan<- <-chan []byte, connection <-chan bool, roster <-chan rosterRequest, broadcast chan<- *res.Packet, world *World) {
 	defer quitWait.Done()
 
 	var (
 		state            = NewState(world)
-		input            [res.Man_count]res.Packet
+		input            = make([]res.Packet, len(state.Mans))
+		connected        = make([]int, len(state.Mans))
 		connection_count int
 		prev             []byte
 		tick             = time.NewTicker(time.Second / TicksPerSecond)
@@ -269,6 +290,7 @@ func Manager(in <-chan *res.Packet, out chan<- <-chan []byte, connection <-chan
 	defer tick.Stop()
 
 	if replay != nil {
+		replay <- append([]byte{2}, EncodeTuning()...)
 		replay <- append(append([]byte{0}, Encode(world)...), Encode(state)...)
 	}
 
@@ -290,7 +312,37 @@ func Manager(in <-chan *res.Packet, out chan<- <-chan []byte, connection <-chan
 		case p := <-in:
 			switch p.GetType() {
 			case res.Type_Input:
-				proto.Merge(&input[p.GetMan()], p)
+				if int(p.GetSlot()) < len(input) {
+					proto.Merge(&input[p.GetSlot()], p)
+				}
+			}
+
+		case r := <-roster:
+			if r.Leave {
+				connected[r.Slot]--
+				input[r.Slot] = res.Packet{}
+				proto.Merge(&input[r.Slot], ReleaseAll)
+				break
+			}
+
+			slot := assignSlot(state, connected, r.Slot, r.Man)
+			for len(input) < len(state.Mans) {
+				input = append(input, res.Packet{})
+				connected = append(connected, 0)
+			}
+			if slot != r.Slot {
+				connected[slot]++
+				if r.Slot != -1 {
+					// carry over the buttons being held down
+					connected[r.Slot]--
+					input[slot] = input[r.Slot]
+					input[r.Slot] = res.Packet{}
+					proto.Merge(&input[r.Slot], ReleaseAll)
+				}
+			}
+			r.Reply <- rosterReply{
+				Slot: slot,
+				Man:  state.Mans[slot].UnitData.(Man).Man(),
 			}
 
 		case out <- ch:
@@ -299,7 +351,7 @@ func Manager(in <-chan *res.Packet, out chan<- <-chan []byte, connection <-chan
 		case <-tick.C:
 			t := state.Tick
 
-			state.Update(&input)
+			state.Update(input)
 
 			cur := Encode(state)
 			diff := bindiff.Diff(prev, cur, 5)
@@ -332,3 +384,55 @@ func Manager(in <-chan *res.Packet, out chan<- <-chan []byte, connection <-chan
 		}
 	}
 }
+
+// assignSlot picks the man a connection should control. prev is the slot it
+// controls now, or -1, and man is the kind of man it wants, or res.Man_count
+// for any. connected counts the connections controlling each slot.
+func assignSlot(state *State, connected []int, prev int, man res.Man) int {
+	if prev != -1 && state.Mans[prev].UnitData.(Man).Man() == man {
+		return prev
+	}
+
+	// check for an empty slot
+	for i := range state.Mans {
+		if connected[i] == 0 && (man == res.Man_count || state.Mans[i].UnitData.(Man).Man() == man) {
+			return i
+		}
+	}
+
+	// nobody else is using our man, so it can change into the one we want
+	if prev != -1 && connected[prev] == 1 {
+		state.SetMan(prev, man)
+		return prev
+	}
+
+	if len(state.Mans) < *flagMaxMans {
+		if man == res.Man_count {
+			// pick whichever kind of man there are fewest of
+			var count [res.Man_count]int
+			for i := range state.Mans {
+				count[state.Mans[i].UnitData.(Man).Man()]++
+			}
+			man = 0
+			for m := range count {
+				if count[m] < count[man] {
+					man = res.Man(m)
+				}
+			}
+		}
+		return state.AddMan(man)
+	}
+
+	if prev != -1 {
+		return prev
+	}
+
+	// multiple people control the same man as a last resort
+	slot := 0
+	for i := range state.Mans {
+		if connected[i] < connected[slot] {
+			slot = i
+		}
+	}
+	return slot
+}
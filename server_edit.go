@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"code.google.com/p/goprotobuf/proto"
+	"encoding/gob"
+	"fmt"
+	"github.com/Rnoadm/wdvn/res"
+	"log"
+	"net"
+	"time"
+)
+
+// EditMaxGrowth is how many tiles past the edge of the level an edit session
+// lets a tile be set, so one packet cannot make the level too big to send.
+const EditMaxGrowth = 1000
+
+// TileChange is a tile set by an editor in an edit session.
+type TileChange struct {
+	At   Coord
+	Tile WorldTile
+}
+
+// decodeTiles reads the tiles in a Type_EditTile packet and checks that they
+// can be put in w.
+func decodeTiles(p *res.Packet, w *World) ([]TileChange, error) {
+	var tiles []TileChange
+	if err := gob.NewDecoder(bytes.NewReader(p.GetData())).Decode(&tiles); err != nil {
+		return nil, err
+	}
+	n, err := tilesetSize(w.Meta.Tileset)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tiles {
+		if w.Outside(t.At.X, t.At.Y) > EditMaxGrowth {
+			return nil, fmt.Errorf("tile %d,%d is too far outside the level", t.At.X, t.At.Y)
+		}
+		if err = t.Tile.check(n); err != nil {
+			return nil, fmt.Errorf("tile %d,%d: %v", t.At.X, t.At.Y, err)
+		}
+	}
+	return tiles, nil
+}
+
+// decodeObjects reads the entities, triggers, and metadata in a
+// Type_EditObjects packet and checks that they can be put in w.
+func decodeObjects(p *res.Packet, w *World) (levelObjects, error) {
+	var o levelObjects
+	if err := gob.NewDecoder(bytes.NewReader(p.GetData())).Decode(&o); err != nil {
+		return o, err
+	}
+	if err := o.Meta.check(); err != nil {
+		return o, err
+	}
+	n, err := tilesetSize(o.Meta.Tileset)
+	if err != nil {
+		return o, err
+	}
+	for i, e := range o.Entities {
+		if e.Kind < 0 || e.Kind >= Entity_count || e.Tile < 0 || e.Tile >= n {
+			return o, fmt.Errorf("entity %d is not valid", i)
+		}
+		if e.Size.X <= 0 || e.Size.Y <= 0 || len(e.Path) == 0 || e.stuck() {
+			return o, fmt.Errorf("entity %d has no size or nowhere to be", i)
+		}
+	}
+	for i, t := range o.Triggers {
+		if t.Event < 0 || t.Event >= TriggerEvent_count {
+			return o, fmt.Errorf("trigger %d is not valid", i)
+		}
+		for _, a := range t.Actions {
+			if a.Kind < 0 || a.Kind >= ActionKind_count {
+				return o, fmt.Errorf("trigger %d has an action that is not valid", i)
+			}
+			// doors that are not wired up are -1.
+			if a.Kind == Action_OpenDoor && (a.Door < -1 || a.Door >= len(o.Entities)) {
+				return o, fmt.Errorf("trigger %d opens door %d, which does not exist", i, a.Door)
+			}
+		}
+	}
+	return o, nil
+}
+
+// applyEdit makes the edit in p, a Type_EditTile or Type_EditObjects packet,
+// to w, if it can be put in w.
+func applyEdit(p *res.Packet, w *World) error {
+	switch p.GetType() {
+	case res.Type_EditTile:
+		tiles, err := decodeTiles(p, w)
+		if err != nil {
+			return err
+		}
+		for _, t := range tiles {
+			w.setTile(t.At, t.Tile)
+		}
+		w.shrink()
+
+	case res.Type_EditObjects:
+		o, err := decodeObjects(p, w)
+		if err != nil {
+			return err
+		}
+		w.setObjects(o)
+	}
+	return nil
+}
+
+// ListenEdit runs an edit session for world on l. Editors that connect get
+// the level, and the changes each of them makes are applied to world in the
+// order they arrive and then sent to every editor, including the one that
+// made them, so they all end up with the same level. An editor whose change
+// cannot be made is sent the level again. world is saved to filename in
+// format when an editor asks and, if it has changed, when the session ends.
+func ListenEdit(l net.Listener, world *World, filename string, format LevelFormat) {
+	defer quitWait.Done()
+	defer l.Close()
+
+	var (
+		accept  = make(chan net.Conn)
+		edits   = make(chan *res.Packet)
+		leave   = make(chan int)
+		editors = make(map[int]chan<- *res.Packet)
+		cursors = make(map[int]*res.Packet)
+		changed bool // edits have been made since world was saved
+	)
+	quitWait.Add(1)
+	go Accept(accept, l)
+
+	broadcast := func(p *res.Packet) {
+		for _, ch := range editors {
+			ch <- p
+		}
+	}
+
+	save := func() bool {
+		if err := saveLevel(filename, world, format); err != nil {
+			log.Println("saving", filename, "failed:", err)
+			return false
+		}
+		log.Println("saved", filename)
+		changed = false
+		return true
+	}
+
+	for {
+		select {
+		case conn := <-accept:
+			slot := 0
+			for editors[slot] != nil {
+				slot++
+			}
+			ch, out := make(chan *res.Packet), make(chan *res.Packet)
+			go Queue(ch, out)
+			editors[slot] = ch
+
+			// the editor's cursor is drawn in the color of this man.
+			ch <- &res.Packet{
+				Type: Type_SelectMan,
+				Man:  res.Man(slot % int(res.Man_count)).Enum(),
+				Slot: proto.Uint32(uint32(slot)),
+			}
+			ch <- &res.Packet{
+				Type: Type_World,
+				Data: Encode(world),
+			}
+			for _, p := range cursors {
+				ch <- p
+			}
+
+			log.Println(conn.RemoteAddr(), "started editing as editor", slot)
+
+			quitWait.Add(1)
+			go ServeEdit(conn, slot, out, edits, func() {
+				select {
+				case leave <- slot:
+				case <-quitRequest:
+				}
+				quitWait.Done()
+			})
+
+		case p := <-edits:
+			switch p.GetType() {
+			case res.Type_EditTile, res.Type_EditObjects:
+				if err := applyEdit(p, world); err != nil {
+					log.Println("editor", p.GetSlot(), "sent a bad edit:", err)
+					// the editor has already made the edit, so it needs
+					// the level as it really is.
+					if ch, ok := editors[int(p.GetSlot())]; ok {
+						ch <- &res.Packet{
+							Type: Type_World,
+							Data: Encode(world),
+						}
+					}
+					continue
+				}
+				changed = true
+
+			case res.Type_EditCursor:
+				cursors[int(p.GetSlot())] = p
+
+			case res.Type_EditSave:
+				// every editor is told the level was saved.
+				if !save() {
+					continue
+				}
+			}
+			broadcast(p)
+
+		case slot := <-leave:
+			close(editors[slot])
+			delete(editors, slot)
+			delete(cursors, slot)
+
+			// a cursor with no position is taken away.
+			broadcast(&res.Packet{
+				Type: Type_EditCursor,
+				Slot: proto.Uint32(uint32(slot)),
+			})
+
+		case <-quitRequest:
+			if changed {
+				save()
+			}
+			return
+		}
+	}
+}
+
+// ServeEdit passes packets between an editor connected to an edit session and
+// ListenEdit.
+func ServeEdit(conn net.Conn, slot int, in <-chan *res.Packet, edits chan<- *res.Packet, disconnect func()) {
+	defer disconnect()
+	defer conn.Close()
+
+	read, write, errors := make(chan *res.Packet), make(chan *res.Packet), make(chan error, 2)
+	defer close(write)
+	go Read(conn, read, errors)
+	go Write(conn, write, errors)
+
+	ping := time.NewTicker(time.Second)
+	defer ping.Stop()
+	lastPing := time.Now()
+
+	for {
+		select {
+		case p, ok := <-in:
+			if !ok {
+				return
+			}
+			write <- p
+
+		case p, ok := <-read:
+			if !ok {
+				// we will return from the error channel.
+				read = nil
+				continue
+			}
+
+			switch p.GetType() {
+			case res.Type_Ping:
+				lastPing = time.Now()
+
+			case res.Type_EditTile, res.Type_EditObjects, res.Type_EditCursor, res.Type_EditSave:
+				p.Slot = proto.Uint32(uint32(slot))
+				select {
+				case edits <- p:
+				case <-quitRequest:
+					return
+				}
+			}
+
+		case <-ping.C:
+			b, err := time.Now().GobEncode()
+			if err != nil {
+				panic(err)
+			}
+			write <- &res.Packet{
+				Type: Type_Ping,
+				Data: b,
+			}
+
+			if time.Since(lastPing) > time.Second*5 {
+				log.Println(conn.RemoteAddr(), "editor", slot, "disconnected: ping timeout")
+				return
+			}
+
+		case err := <-errors:
+			log.Println(conn.RemoteAddr(), "editor", slot, "disconnected:", err)
+			return
+
+		case <-quitRequest:
+			return
+		}
+	}
+}
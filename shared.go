@@ -20,26 +20,14 @@ const (
 	MinimumVelocity  = PixelSize * 20             // unit stops moving if on ground
 	TerminalVelocity = 100 * TileSize * PixelSize // unit cannot move faster on x or y than this
 	Friction         = 100                        // 1/Friction of the velocity is removed per tick
+	LiquidFriction   = 10                         // 1/LiquidFriction of the velocity is removed per tick in liquid
 	TicksPerSecond   = 100
-	WhipTimeMin      = 0.2 * TicksPerSecond
-	WhipTimeMax      = 1.5 * TicksPerSecond
-	WhipDamageMin    = 10
-	WhipDamageMax    = 5000
-	WhipSpeedMin     = 200 * PixelSize
-	WhipSpeedMax     = 1500 * PixelSize
-	WhipDistance     = 10 * TileSize * PixelSize
 	ManLives         = 10
 	ManHealth        = 10000
 	DamageFactor     = TileSize * PixelSize * 100 // momentum/DamageFactor is damage dealt
 	RespawnTime      = 2 * TicksPerSecond
 	FloaterFadeStart = 0.5 * TicksPerSecond
 	FloaterFadeEnd   = 1.5 * TicksPerSecond
-	LemonSpeed       = 1000 * PixelSize
-	LemonTime        = 0.3 * TicksPerSecond
-	VacuumHurt       = TicksPerSecond / 5
-	VacuumSpeed      = 100 * PixelSize
-	VacuumDistance   = 1000 * PixelSize
-	VacuumSuck       = 20
 )
 
 type Side uint8
@@ -52,17 +40,23 @@ const (
 )
 
 var (
-	Type_Ping      = res.Type_Ping.Enum()
-	Type_SelectMan = res.Type_SelectMan.Enum()
-	Type_Input     = res.Type_Input.Enum()
-	Type_StateDiff = res.Type_StateDiff.Enum()
-	Type_FullState = res.Type_FullState.Enum()
-	Type_World     = res.Type_World.Enum()
+	Type_Ping        = res.Type_Ping.Enum()
+	Type_SelectMan   = res.Type_SelectMan.Enum()
+	Type_Input       = res.Type_Input.Enum()
+	Type_StateDiff   = res.Type_StateDiff.Enum()
+	Type_FullState   = res.Type_FullState.Enum()
+	Type_World       = res.Type_World.Enum()
+	Type_Tuning      = res.Type_Tuning.Enum()
+	Type_EditTile    = res.Type_EditTile.Enum()
+	Type_EditObjects = res.Type_EditObjects.Enum()
+	Type_EditCursor  = res.Type_EditCursor.Enum()
+	Type_EditSave    = res.Type_EditSave.Enum()
 
 	Man_Whip    = res.Man_Whip.Enum()
 	Man_Density = res.Man_Density.Enum()
 	Man_Vacuum  = res.Man_Vacuum.Enum()
 	Man_Normal  = res.Man_Normal.Enum()
+	Man_Portal  = res.Man_Portal.Enum()
 
 	Button_released = res.Button_released.Enum()
 	Button_pressed  = res.Button_pressed.Enum()
@@ -80,52 +74,82 @@ var (
 
 type State struct {
 	Tick       uint64
-	Mans       [res.Man_count]Unit
+	Mans       []Unit
 	Floaters   []Floater
 	SpawnPoint Coord
 	Units      map[uint64]*Unit
 	NextUnit   uint64
+	Broken     map[Coord]bool // fragile tiles that have been broken
+	Scores     []Score        // versus mode points, one per man
+	Stats      []Stats        // one per man
+	RoundEnd   uint64         // tick the versus round ends
+	Opened     map[int]uint64 // tick each door entity's switch was pressed
+	Fired      map[int]uint64 // tick each trigger last fired
+	TimeUp     uint64         // tick the level's time limit runs out
+
+	pushing int     // entity being moved plus one, which Trace ignores
+	killed  []Coord // where units died this tick
+	pressed []Coord // switch tiles stood on this tick
 
 	world *World
+	grid  *unitGrid
 }
 
 func NewState(world *World) *State {
 	var state State
 	state.world = world
-	state.Mans[res.Man_Whip].UnitData = &WhipMan{
-		ManUnitData: ManUnitData{
-			Man_:        res.Man_Whip,
-			Lives_:      ManLives,
-			Checkpoint_: state.SpawnPoint,
-		},
+	state.Units = make(map[uint64]*Unit)
+	state.SpawnPoint = world.Meta.Spawn
+	if !Rules.Versus {
+		state.TimeUp = world.Meta.TimeLimit
 	}
-	state.Mans[res.Man_Density].UnitData = &DensityMan{
-		ManUnitData: ManUnitData{
-			Man_:        res.Man_Density,
-			Lives_:      ManLives,
-			Checkpoint_: state.SpawnPoint,
-		},
+	for man := res.Man(0); man < res.Man_count; man++ {
+		state.AddMan(man)
 	}
-	state.Mans[res.Man_Vacuum].UnitData = &VacuumMan{
-		ManUnitData: ManUnitData{
-			Man_:        res.Man_Vacuum,
-			Lives_:      ManLives,
-			Checkpoint_: state.SpawnPoint,
-		},
+	return &state
+}
+
+// AddMan adds a man to the roster and returns its slot in Mans.
+func (state *State) AddMan(man res.Man) int {
+	lives := int64(ManLives)
+	if state.world.Meta.Lives > 0 {
+		lives = state.world.Meta.Lives
 	}
-	state.Mans[res.Man_Normal].UnitData = &NormalMan{
-		ManUnitData: ManUnitData{
-			Man_:        res.Man_Normal,
-			Lives_:      ManLives,
+	state.Mans = append(state.Mans, Unit{
+		UnitData: NewMan(man, ManUnitData{
+			Lives_:      lives,
 			Checkpoint_: state.SpawnPoint,
-		},
+		}),
+	})
+	state.Scores = append(state.Scores, Score{})
+	state.Stats = append(state.Stats, Stats{})
+	// the append may have moved every man, so the grid is out of date.
+	state.grid = nil
+
+	slot := len(state.Mans) - 1
+	u := &state.Mans[slot]
+	u.Health = u.MaxHealth(state, u)
+	state.FindSpawnPosition(u)
+	return slot
+}
+
+// SetMan changes the man in a slot to a different class. The unit keeps its
+// position, lives and checkpoint.
+func (state *State) SetMan(slot int, man res.Man) {
+	u := &state.Mans[slot]
+	m := u.UnitData.(Man)
+	if m.Man() == man {
+		return
 	}
-	for i := range state.Mans {
-		state.Mans[i].Health = state.Mans[i].MaxHealth(&state, &state.Mans[i])
-		state.FindSpawnPosition(&state.Mans[i])
+	if v, ok := m.(*VacuumMan); ok {
+		v.Drop(state, u)
 	}
-	state.Units = make(map[uint64]*Unit)
-	return &state
+
+	u.UnitData = NewMan(man, *m.Base())
+	if max := u.MaxHealth(state, u); u.Health > max {
+		u.Health = max
+	}
+	state.UnitMoved(u)
 }
 
 type Floater struct {
@@ -164,37 +188,118 @@ func (state *State) FindSpawnPosition(u *Unit) {
 		}
 		return state.SpawnPoint
 	}(u.Size(state, u))
+	state.UnitMoved(u)
 }
 
-func (state *State) EachUnit(f func(*Unit)) {
-	var ignore *Unit
-	if m, ok := state.Mans[res.Man_Vacuum].UnitData.(*VacuumMan); ok {
-		ignore = m.Held(state)
+// heldUnits returns the units being held by VacuumMans, which are not
+// considered part of the world until they are released.
+func (state *State) heldUnits() []*Unit {
+	var held []*Unit
+	for i := range state.Mans {
+		if m, ok := state.Mans[i].UnitData.(*VacuumMan); ok {
+			held = append(held, m.Held(state)...)
+		}
 	}
+	return held
+}
+
+func isHeld(held []*Unit, u *Unit) bool {
+	for _, h := range held {
+		if h == u {
+			return true
+		}
+	}
+	return false
+}
+
+func (state *State) EachUnit(f func(*Unit)) {
+	held := state.heldUnits()
 
 	for i := range state.Mans {
-		if &state.Mans[i] != ignore {
+		if !isHeld(held, &state.Mans[i]) {
 			f(&state.Mans[i])
 		}
 	}
 	for _, u := range state.Units {
-		if u != ignore {
+		if !isHeld(held, u) {
 			f(u)
 		}
 	}
 }
 
-func (state *State) Update(input *[res.Man_count]res.Packet) {
+// Solid is like World.Solid, but broken tiles are not solid.
+func (state *State) Solid(x, y int64) bool {
+	return state.world.Solid(x, y) && !state.Broken[Coord{x, y}]
+}
+
+func (state *State) BreakTile(x, y int64) {
+	if state.Broken == nil {
+		state.Broken = make(map[Coord]bool)
+	}
+	state.Broken[Coord{x, y}] = true
+}
+
+// InLiquid reports whether the middle of u is in a liquid tile.
+func (state *State) InLiquid(u *Unit) bool {
+	pos := u.Position.Sub(Coord{0, u.Size(state, u).Y / 2}).Floor(TileSize * PixelSize)
+	x, y := pos.X/TileSize/PixelSize, pos.Y/TileSize/PixelSize
+	return !state.Solid(x, y) && state.world.Special(x, y) == SpecialTile_Liquid
+}
+
+// UpdateTimeLimit kills every man when the level's time limit runs out and
+// starts the clock again.
+func (state *State) UpdateTimeLimit() {
+	if Rules.Versus || state.TimeUp == 0 || state.Tick < state.TimeUp {
+		return
+	}
+	state.TimeUp = state.Tick + state.world.Meta.TimeLimit
+
+	for i := range state.Mans {
+		u := &state.Mans[i]
+		if u.Health <= 0 {
+			continue
+		}
+		state.Floaters = append(state.Floaters, Floater{
+			S:  "TIME UP!",
+			Fg: color.RGBA{255, 255, 255, 255},
+			Bg: u.Color(state, u),
+			X:  u.Position.X,
+			Y:  u.Position.Y - u.Size(state, u).Y,
+			T:  state.Tick,
+		})
+		u.Kill(state, nil, DamageTime)
+	}
+}
+
+func (state *State) Update(input []res.Packet) {
 	state.Tick++
+	state.UpdateRound()
+	state.UpdateTimeLimit()
+	state.MoveEntities()
 
 	for i := range state.Mans {
-		state.Mans[i].UnitData.(Man).Input(&(*input)[i])
+		var p *res.Packet
+		if i < len(input) {
+			p = &input[i]
+		}
+		state.Mans[i].UnitData.(Man).Input(p)
 	}
 
+	state.grid = newUnitGrid(state)
+
 	state.EachUnit(func(u *Unit) {
 		u.Update(state)
+		state.UnitMoved(u)
 	})
 
+	for i := range state.Mans {
+		if state.Mans[i].Health > 0 && i < len(state.Stats) {
+			state.Stats[i].TimeAlive++
+		}
+	}
+
+	state.UpdateTriggers()
+
 	for i, l := 0, len(state.Floaters); i < l; i++ {
 		if state.Floaters[i].T < state.Tick-FloaterFadeEnd {
 			state.Floaters = append(state.Floaters[:i], state.Floaters[i+1:]...)
@@ -216,6 +321,7 @@ type Trace struct {
 	Units    []TraceUnit
 	HitWorld bool
 	Special  SpecialTile
+	Shape    TileShape
 	Side
 }
 
@@ -258,18 +364,18 @@ func (state *State) Trace(start, end, hull Coord, worldOnly bool) *Trace {
 	delta := end.Sub(start)
 	maxDist := int64(1<<63 - 1)
 
-	traceAABB := func(mins, maxs Coord) (dist, x, y int64, side Side) {
+	sweepAABB := func(mins, maxs Coord) (enter, exit float64, side Side, ok bool) {
 		if delta.X >= 0 && (min.X >= maxs.X || max.X+delta.X <= mins.X) {
-			return -1, 0, 0, 0
+			return
 		}
 		if delta.X <= 0 && (min.X+delta.X >= maxs.X || max.X <= mins.X) {
-			return -1, 0, 0, 0
+			return
 		}
 		if delta.Y >= 0 && (min.Y >= maxs.Y || max.Y+delta.Y <= mins.Y) {
-			return -1, 0, 0, 0
+			return
 		}
 		if delta.Y <= 0 && (min.Y+delta.Y >= maxs.Y || max.Y <= mins.Y) {
-			return -1, 0, 0, 0
+			return
 		}
 
 		var xEnter, xExit float64
@@ -296,12 +402,8 @@ func (state *State) Trace(start, end, hull Coord, worldOnly bool) *Trace {
 			yExit = math.Inf(1)
 		}
 
-		enter := math.Max(xEnter, yEnter)
-		exit := math.Min(xExit, yExit)
-
-		if exit < 0 || enter > 1 || enter > exit {
-			return -1, 0, 0, 0
-		}
+		enter = math.Max(xEnter, yEnter)
+		exit = math.Min(xExit, yExit)
 
 		if xEnter > yEnter {
 			if delta.X > 0 {
@@ -317,6 +419,15 @@ func (state *State) Trace(start, end, hull Coord, worldOnly bool) *Trace {
 			}
 		}
 
+		ok = true
+		return
+	}
+
+	traceResult := func(enter, exit float64, side Side) (dist, x, y int64, s Side) {
+		if exit < 0 || enter > 1 || enter > exit {
+			return -1, 0, 0, 0
+		}
+
 		x = int64(enter * float64(delta.X))
 		y = int64(enter * float64(delta.Y))
 
@@ -325,7 +436,81 @@ func (state *State) Trace(start, end, hull Coord, worldOnly bool) *Trace {
 			dist, x, y = 0, 0, 0
 		}
 
-		return
+		return dist, x, y, side
+	}
+
+	traceAABB := func(mins, maxs Coord) (dist, x, y int64, side Side) {
+		enter, exit, side, ok := sweepAABB(mins, maxs)
+		if !ok {
+			return -1, 0, 0, 0
+		}
+		return traceResult(enter, exit, side)
+	}
+
+	traceTile := func(mins, maxs Coord, shape TileShape) (dist, x, y int64, side Side) {
+		if shape == TileShape_Full {
+			return traceAABB(mins, maxs)
+		}
+
+		left := mins.Y + shape.Left()*PixelSize
+		right := mins.Y + shape.Right()*PixelSize
+		top := left
+		if right < top {
+			top = right
+		}
+
+		enter, exit, side, ok := sweepAABB(Coord{mins.X, top}, maxs)
+		if !ok {
+			return -1, 0, 0, 0
+		}
+
+		if shape.Sloped() {
+			// separating axis perpendicular to the surface of the slope
+			normal := shape.Normal()
+			nx, ny := float64(normal.X), float64(normal.Y)
+			project := func(x, y int64) float64 {
+				return float64(x)*nx + float64(y)*ny
+			}
+
+			surface := project(mins.X, left)
+			bottom := math.Min(project(mins.X, maxs.Y), project(maxs.X, maxs.Y))
+			near, far := project(max.X, max.Y), project(min.X, min.Y)
+			if nx > 0 {
+				near, far = project(min.X, max.Y), project(max.X, min.Y)
+			}
+			speed := project(delta.X, delta.Y)
+
+			var sEnter, sExit float64
+			if speed > 0 {
+				if near >= surface || far+speed <= bottom {
+					return -1, 0, 0, 0
+				}
+				sEnter = (bottom - far) / speed
+				sExit = (surface - near) / speed
+			} else if speed < 0 {
+				if near+speed >= surface || far <= bottom {
+					return -1, 0, 0, 0
+				}
+				sEnter = (surface - near) / speed
+				sExit = (bottom - far) / speed
+			} else {
+				if near >= surface || far <= bottom {
+					return -1, 0, 0, 0
+				}
+				sEnter = math.Inf(-1)
+				sExit = math.Inf(1)
+			}
+
+			if sEnter >= enter {
+				enter = sEnter
+				side = SideTop
+			}
+			if sExit < exit {
+				exit = sExit
+			}
+		}
+
+		return traceResult(enter, exit, side)
 	}
 
 	traceUnit := func(u *Unit) (dist, x, y int64, side Side) {
@@ -356,26 +541,45 @@ func (state *State) Trace(start, end, hull Coord, worldOnly bool) *Trace {
 	} else {
 		bounds_max.Y += delta.Y
 	}
+	units_min, units_max := bounds_min, bounds_max
 	bounds_min = bounds_min.Floor(TileSize * PixelSize)
 	bounds_max = bounds_max.Floor(TileSize * PixelSize).Add(Coord{TileSize * PixelSize, TileSize * PixelSize})
 
 	for x := bounds_min.X; x <= bounds_max.X; x += TileSize * PixelSize {
 		for y := bounds_min.Y; y <= bounds_max.Y; y += TileSize * PixelSize {
-			if state.world.Solid(x/TileSize/PixelSize, y/TileSize/PixelSize) {
-				dist, dx, dy, side := traceAABB(Coord{x, y}, Coord{x + TileSize*PixelSize, y + TileSize*PixelSize})
+			if state.Solid(x/TileSize/PixelSize, y/TileSize/PixelSize) {
+				shape := state.world.Shape(x/TileSize/PixelSize, y/TileSize/PixelSize)
+				dist, dx, dy, side := traceTile(Coord{x, y}, Coord{x + TileSize*PixelSize, y + TileSize*PixelSize}, shape)
 				if dist >= 0 && (dist < maxDist || (dist == maxDist && tr.Special == SpecialTile_None)) {
 					maxDist = dist
 					tr.HitWorld = true
 					tr.End = start.Add(Coord{dx, dy})
 					tr.Special = state.world.Special(x/TileSize/PixelSize, y/TileSize/PixelSize)
+					tr.Shape = shape
 					tr.Side = side
 				}
 			}
 		}
 	}
 
+	for i := range state.world.Entities {
+		if state.pushing == i+1 {
+			continue
+		}
+		mins, maxs := state.EntityBox(i, state.Tick)
+		dist, dx, dy, side := traceAABB(mins, maxs)
+		if dist >= 0 && dist < maxDist {
+			maxDist = dist
+			tr.HitWorld = true
+			tr.End = start.Add(Coord{dx, dy})
+			tr.Special = SpecialTile_None
+			tr.Shape = TileShape_Full
+			tr.Side = side
+		}
+	}
+
 	if !worldOnly {
-		state.EachUnit(func(u *Unit) {
+		state.EachUnitIn(units_min, units_max, func(u *Unit) {
 			if dist, x, y, side := traceUnit(u); dist >= 0 && dist <= maxDist {
 				tr.Units = append(tr.Units, TraceUnit{Unit: u, Dist: dist, X: start.X + x, Y: start.Y + y, Side: side})
 			}
@@ -388,12 +592,13 @@ func (state *State) Trace(start, end, hull Coord, worldOnly bool) *Trace {
 
 var FooLevel = LoadWorld(strings.NewReader(res.FooLevel))
 
-func LoadWorld(r io.Reader) (w *World) {
-	err := gob.NewDecoder(r).Decode(&w)
+// LoadWorld reads a level in either format. See DecodeWorld.
+func LoadWorld(r io.Reader) *World {
+	w, err := DecodeWorld(r)
 	if err != nil {
 		panic(err)
 	}
-	return
+	return w
 }
 
 func Encode(v interface{}) []byte {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Stats are what a man has done since joining.
+type Stats struct {
+	Dealt       [DamageCause_count]int64 // damage this man is to blame for, by cause
+	Taken       [DamageCause_count]int64
+	Deaths      [DamageCause_count]int64
+	Checkpoints int64
+	TimeAlive   uint64 // in ticks
+}
+
+// stats returns the Stats of u, or nil if u is not a man.
+func (state *State) stats(u *Unit) *Stats {
+	if u == nil {
+		return nil
+	}
+	slot := state.Ref(u).Man - 1
+	if slot < 0 || slot >= len(state.Stats) {
+		return nil
+	}
+	return &state.Stats[slot]
+}
+
+// Blame returns the man responsible for damage to u: by if it is a man, or
+// otherwise the man who last hurt u if that was recent, so a man knocked off
+// a ledge can blame whoever knocked them.
+func (state *State) Blame(u, by *Unit) *Unit {
+	if by != nil && by.IsMan() {
+		if by != u && u.IsMan() {
+			m := u.UnitData.(Man).Base()
+			m.HurtBy_, m.HurtAt_ = state.Ref(by), state.Tick
+		}
+		return by
+	}
+	if !u.IsMan() {
+		return nil
+	}
+
+	m := u.UnitData.(Man).Base()
+	if m.HurtBy_.Zero() || state.Tick-m.HurtAt_ > Rules.KnockOutCredit {
+		return nil
+	}
+	return state.Deref(m.HurtBy_)
+}
+
+// Record adds amount damage dealt to u, which credit is to blame for, to the
+// stats.
+func (state *State) Record(u, credit *Unit, amount int64, cause DamageCause) {
+	if s := state.stats(credit); s != nil && credit != u {
+		s.Dealt[cause] += amount
+	}
+	if s := state.stats(u); s != nil {
+		s.Taken[cause] += amount
+		if u.Health == 0 {
+			s.Deaths[cause]++
+		}
+	}
+}
+
+type statsJSON struct {
+	Slot        int
+	Man         string
+	Dealt       map[string]int64
+	Taken       map[string]int64
+	Deaths      map[string]int64
+	Checkpoints int64
+	TimeAlive   float64 // in seconds
+	Points      int64   `json:",omitempty"`
+}
+
+// WriteStats writes the stats of every man in state as JSON.
+func WriteStats(w io.Writer, state *State) error {
+	byCause := func(a *[DamageCause_count]int64) map[string]int64 {
+		m := make(map[string]int64)
+		for c, n := range a {
+			if n != 0 {
+				m[DamageCause(c).String()] = n
+			}
+		}
+		return m
+	}
+
+	out := make([]statsJSON, len(state.Stats))
+	for i := range state.Stats {
+		s := &state.Stats[i]
+		out[i] = statsJSON{
+			Slot:        i + 1,
+			Dealt:       byCause(&s.Dealt),
+			Taken:       byCause(&s.Taken),
+			Deaths:      byCause(&s.Deaths),
+			Checkpoints: s.Checkpoints,
+			TimeAlive:   (time.Duration(s.TimeAlive) * time.Second / TicksPerSecond).Seconds(),
+		}
+		if i < len(state.Mans) {
+			out[i].Man = state.Mans[i].UnitData.(Man).Man().String()
+		}
+		if Rules.Versus && i < len(state.Scores) {
+			out[i].Points = state.Scores[i].Points()
+		}
+	}
+
+	b, err := json.MarshalIndent(out, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// ReplayStats writes the stats from the end of a replay as JSON.
+func ReplayStats(w io.Writer, r io.Reader) error {
+	var last *State
+	err := ReadReplay(r, func(state *State) {
+		last = state
+	})
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		return fmt.Errorf("replay has no states")
+	}
+	return WriteStats(w, last)
+}
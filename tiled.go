@@ -0,0 +1,764 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Rnoadm/wdvn/res"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Levels can be edited in Tiled (http://www.mapeditor.org/) by converting
+// them to its JSON map format. The map has three layers:
+//
+// "Tiles" uses the terrain tileset, whose tiles are the ones in terrain.png.
+// An empty cell is tile 0.
+//
+// "Solid" uses the shapes tileset. The first TileShape_count tiles are solid
+// tiles of each shape and the rest are the same shapes without being solid.
+// An empty cell is a tile that is not solid.
+//
+// "Objects" holds everything else. The point named "spawn" is where mans
+// start. Special tiles are rectangles with the
+// type "special" named after the special tile. Entities and triggers are
+// rectangles with the entity kind or "trigger" as their type, and each action
+// is a point with the action kind as its type and a "trigger" property
+// pointing at the trigger it belongs to.
+//
+// The rest of the level's LevelMeta, and where the map is in the world, are
+// properties of the map.
+//
+// terrain.png and shapes.png from the res directory need to be next to the
+// map for Tiled to show the tiles.
+
+type tiledMap struct {
+	Type         string          `json:"type"`
+	Version      string          `json:"version"`
+	Orientation  string          `json:"orientation"`
+	RenderOrder  string          `json:"renderorder"`
+	Width        int64           `json:"width"`
+	Height       int64           `json:"height"`
+	TileWidth    int64           `json:"tilewidth"`
+	TileHeight   int64           `json:"tileheight"`
+	Infinite     bool            `json:"infinite"`
+	NextLayerID  int             `json:"nextlayerid"`
+	NextObjectID int             `json:"nextobjectid"`
+	Layers       []tiledLayer    `json:"layers"`
+	Tilesets     []tiledTileset  `json:"tilesets"`
+	Properties   tiledProperties `json:"properties,omitempty"`
+}
+
+type tiledLayer struct {
+	ID        int           `json:"id"`
+	Name      string        `json:"name"`
+	Type      string        `json:"type"`
+	X         int64         `json:"x"`
+	Y         int64         `json:"y"`
+	Width     int64         `json:"width,omitempty"`
+	Height    int64         `json:"height,omitempty"`
+	Opacity   float64       `json:"opacity"`
+	Visible   bool          `json:"visible"`
+	Data      []uint32      `json:"data,omitempty"`
+	DrawOrder string        `json:"draworder,omitempty"`
+	Objects   []tiledObject `json:"objects,omitempty"`
+}
+
+type tiledObject struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Class      string          `json:"class,omitempty"` // Tiled 1.9 renamed type to class
+	X          float64         `json:"x"`
+	Y          float64         `json:"y"`
+	Width      float64         `json:"width"`
+	Height     float64         `json:"height"`
+	Rotation   float64         `json:"rotation"`
+	Visible    bool            `json:"visible"`
+	Point      bool            `json:"point,omitempty"`
+	Properties tiledProperties `json:"properties,omitempty"`
+}
+
+type tiledProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type tiledTileset struct {
+	FirstGID    uint32      `json:"firstgid"`
+	Source      string      `json:"source,omitempty"`
+	Name        string      `json:"name"`
+	TileWidth   int64       `json:"tilewidth"`
+	TileHeight  int64       `json:"tileheight"`
+	TileCount   int         `json:"tilecount"`
+	Columns     int         `json:"columns"`
+	Image       string      `json:"image"`
+	ImageWidth  int         `json:"imagewidth"`
+	ImageHeight int         `json:"imageheight"`
+	Tiles       []tiledTile `json:"tiles,omitempty"`
+}
+
+type tiledTile struct {
+	ID         int             `json:"id"`
+	Properties tiledProperties `json:"properties"`
+}
+
+// tiledFlipped holds the bits Tiled sets in a gid when a tile is flipped or
+// rotated. Flipping does not mean anything here, so they are ignored.
+const tiledFlipped = 0xf0000000
+
+// IsTiledLevel reports whether b looks like a Tiled JSON map.
+func IsTiledLevel(b []byte) bool {
+	if !IsTextLevel(b) {
+		return false
+	}
+	var m struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(b, &m) == nil && m.Type == "map"
+}
+
+func (o *tiledObject) class() string {
+	if o.Type == "" {
+		return o.Class
+	}
+	return o.Type
+}
+
+func (o *tiledObject) propString(name string) (string, error) {
+	return o.Properties.getString(fmt.Sprintf("object %d", o.ID), name)
+}
+
+func (o *tiledObject) propInt(name string) (int64, error) {
+	return o.Properties.getInt(fmt.Sprintf("object %d", o.ID), name)
+}
+
+type tiledProperties []tiledProperty
+
+func (ps tiledProperties) get(name string) (interface{}, bool) {
+	for _, p := range ps {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return nil, false
+}
+
+// getString returns the property called name, or "" if there is none. what
+// says what the properties belong to for errors.
+func (ps tiledProperties) getString(what, name string) (string, error) {
+	v, ok := ps.get(name)
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("tiled: %s property %q must be a string", what, name)
+	}
+	return s, nil
+}
+
+// getInt returns the property called name, or 0 if there is none.
+func (ps tiledProperties) getInt(what, name string) (int64, error) {
+	v, ok := ps.get(name)
+	if !ok {
+		return 0, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f != float64(int64(f)) {
+		return 0, fmt.Errorf("tiled: %s property %q must be a whole number", what, name)
+	}
+	return int64(f), nil
+}
+
+// tile returns the tile the top left corner of o is in.
+func (o *tiledObject) tile() Coord {
+	return Coord{int64(math.Floor(o.X / TileSize)), int64(math.Floor(o.Y / TileSize))}
+}
+
+// tiles returns the first and last tile o covers.
+func (o *tiledObject) tiles() (min, max Coord) {
+	min = o.tile()
+	max = Coord{int64(math.Ceil((o.X+o.Width)/TileSize)) - 1, int64(math.Ceil((o.Y+o.Height)/TileSize)) - 1}
+	if max.X < min.X {
+		max.X = min.X
+	}
+	if max.Y < min.Y {
+		max.Y = min.Y
+	}
+	return
+}
+
+func tiledRect(id int, class string, min, max Coord) tiledObject {
+	return tiledObject{
+		ID:      id,
+		Type:    class,
+		X:       float64(min.X * TileSize),
+		Y:       float64(min.Y * TileSize),
+		Width:   float64((max.X - min.X + 1) * TileSize),
+		Height:  float64((max.Y - min.Y + 1) * TileSize),
+		Visible: true,
+	}
+}
+
+func tiledPoint(id int, class string, x, y int64) tiledObject {
+	return tiledObject{
+		ID:      id,
+		Type:    class,
+		X:       float64(x),
+		Y:       float64(y),
+		Visible: true,
+		Point:   true,
+	}
+}
+
+// formatTiles writes tile coordinates as "x,y x,y ...".
+func formatTiles(tiles []Coord) string {
+	s := make([]string, len(tiles))
+	for i, c := range tiles {
+		s[i] = strconv.FormatInt(c.X, 10) + "," + strconv.FormatInt(c.Y, 10)
+	}
+	return strings.Join(s, " ")
+}
+
+func parseTiles(s string) ([]Coord, error) {
+	var tiles []Coord
+	for _, f := range strings.Fields(s) {
+		xy := strings.Split(f, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("tiled: %q is not a tile coordinate", f)
+		}
+		x, err := strconv.ParseInt(xy[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseInt(xy[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, Coord{x, y})
+	}
+	return tiles, nil
+}
+
+func tiledTerrain(firstgid uint32, tiles int) (tiledTileset, error) {
+	c, err := png.DecodeConfig(strings.NewReader(res.TerrainPng))
+	if err != nil {
+		return tiledTileset{}, err
+	}
+	count := c.Width / TileSize
+	if tiles > count {
+		count = tiles
+	}
+	return tiledTileset{
+		FirstGID:    firstgid,
+		Name:        "terrain",
+		TileWidth:   TileSize,
+		TileHeight:  TileSize,
+		TileCount:   count,
+		Columns:     count,
+		Image:       "terrain.png",
+		ImageWidth:  c.Width,
+		ImageHeight: c.Height,
+	}, nil
+}
+
+func tiledShapes(firstgid uint32) tiledTileset {
+	ts := tiledTileset{
+		FirstGID:    firstgid,
+		Name:        "shapes",
+		TileWidth:   TileSize,
+		TileHeight:  TileSize,
+		TileCount:   TileShape_count * 2,
+		Columns:     TileShape_count,
+		Image:       "shapes.png",
+		ImageWidth:  TileSize * TileShape_count,
+		ImageHeight: TileSize * 2,
+	}
+	for i := 0; i < ts.TileCount; i++ {
+		ts.Tiles = append(ts.Tiles, tiledTile{
+			ID: i,
+			Properties: tiledProperties{
+				{"shape", "string", tileShape_names[i%TileShape_count]},
+				{"solid", "bool", i < TileShape_count},
+			},
+		})
+	}
+	return ts
+}
+
+// EncodeTiled writes w as a Tiled JSON map.
+func EncodeTiled(out io.Writer, w *World) error {
+	// map coordinates start at 0.
+	toMap := func(c Coord) Coord {
+		return c.Sub(w.Min)
+	}
+
+	maxTile := 0
+	for _, t := range w.Tiles {
+		if t.Tile < 0 {
+			return fmt.Errorf("tiled: negative tile index %d", t.Tile)
+		}
+		if t.Tile >= maxTile {
+			maxTile = t.Tile + 1
+		}
+	}
+	terrain, err := tiledTerrain(1, maxTile)
+	if err != nil {
+		return err
+	}
+	shapes := tiledShapes(1 + uint32(terrain.TileCount))
+
+	width, height := w.Max.X-w.Min.X+1, w.Max.Y-w.Min.Y+1
+	tiles := make([]uint32, width*height)
+	solid := make([]uint32, width*height)
+	for y := w.Min.Y; y <= w.Max.Y; y++ {
+		for x := w.Min.X; x <= w.Max.X; x++ {
+			i, _ := w.index(x, y)
+			t := w.Tiles[i]
+			j := (y-w.Min.Y)*width + (x - w.Min.X)
+			if t.Tile != 0 {
+				tiles[j] = terrain.FirstGID + uint32(t.Tile)
+			}
+			if t.Solid {
+				solid[j] = shapes.FirstGID + uint32(t.Shape)
+			} else if t.Shape != TileShape_Full {
+				solid[j] = shapes.FirstGID + TileShape_count + uint32(t.Shape)
+			}
+		}
+	}
+
+	var objects []tiledObject
+	nextID := 1
+	add := func(o tiledObject) int {
+		o.ID = nextID
+		nextID++
+		objects = append(objects, o)
+		return o.ID
+	}
+
+	// the spawn point is in Coord units, which are whole fractions of a
+	// pixel, so it survives being a float.
+	origin := toMap(Coord{0, 0})
+	spawn := tiledPoint(0, "", 0, 0)
+	spawn.Name = "spawn"
+	spawn.X = float64(origin.X*TileSize) + float64(w.Meta.Spawn.X)/PixelSize
+	spawn.Y = float64(origin.Y*TileSize) + float64(w.Meta.Spawn.Y)/PixelSize
+	add(spawn)
+
+	// each run of the same special tile in a row is one rectangle.
+	for y := w.Min.Y; y <= w.Max.Y; y++ {
+		for x := w.Min.X; x <= w.Max.X; x++ {
+			s := w.Special(x, y)
+			if s == SpecialTile_None {
+				continue
+			}
+			end := x
+			for end < w.Max.X && w.Special(end+1, y) == s {
+				end++
+			}
+			o := tiledRect(0, "special", toMap(Coord{x, y}), toMap(Coord{end, y}))
+			o.Name = specialTile_names[s]
+			add(o)
+			x = end
+		}
+	}
+
+	entities := make([]int, len(w.Entities))
+	for i, e := range w.Entities {
+		if len(e.Path) == 0 {
+			return fmt.Errorf("tiled: entity %d has no path", i)
+		}
+		path := make([]Coord, len(e.Path))
+		for j, c := range e.Path {
+			path[j] = toMap(c)
+		}
+		o := tiledRect(0, entityKind_names[e.Kind], path[0], path[0].Add(e.Size).Sub(Coord{1, 1}))
+		o.Properties = tiledProperties{
+			{"path", "string", formatTiles(path[1:])},
+			{"speed", "int", e.Speed},
+			{"tile", "int", e.Tile},
+		}
+		if e.Kind == Entity_Door {
+			o.Properties = append(o.Properties, tiledProperty{"switch", "string", formatTiles([]Coord{toMap(e.Switch)})})
+		}
+		entities[i] = add(o)
+	}
+
+	triggers := make([]int, len(w.Triggers))
+	for i, t := range w.Triggers {
+		o := tiledRect(0, "trigger", toMap(t.Min), toMap(t.Max))
+		o.Properties = tiledProperties{
+			{"event", "string", triggerEvent_names[t.Event]},
+			{"repeat", "int", t.Repeat},
+		}
+		triggers[i] = add(o)
+	}
+	for i, t := range w.Triggers {
+		for _, a := range t.Actions {
+			at := toMap(a.At)
+			o := tiledPoint(0, actionKind_names[a.Kind], at.X*TileSize+TileSize/2, at.Y*TileSize+TileSize/2)
+			o.Properties = tiledProperties{
+				{"trigger", "object", triggers[i]},
+			}
+			if a.Kind == Action_OpenDoor && a.Door >= 0 && a.Door < len(entities) {
+				o.Properties = append(o.Properties, tiledProperty{"door", "object", entities[a.Door]})
+			}
+			if a.Text != "" {
+				o.Properties = append(o.Properties, tiledProperty{"text", "string", a.Text})
+			}
+			add(o)
+		}
+	}
+
+	m := tiledMap{
+		Type:         "map",
+		Version:      "1.2",
+		Orientation:  "orthogonal",
+		RenderOrder:  "right-down",
+		Width:        width,
+		Height:       height,
+		TileWidth:    TileSize,
+		TileHeight:   TileSize,
+		NextLayerID:  4,
+		NextObjectID: nextID,
+		Layers: []tiledLayer{
+			{ID: 1, Name: "Tiles", Type: "tilelayer", Width: width, Height: height, Opacity: 1, Visible: true, Data: tiles},
+			{ID: 2, Name: "Solid", Type: "tilelayer", Width: width, Height: height, Opacity: 0.5, Visible: true, Data: solid},
+			{ID: 3, Name: "Objects", Type: "objectgroup", Opacity: 1, Visible: true, DrawOrder: "index", Objects: objects},
+		},
+		Tilesets: []tiledTileset{terrain, shapes},
+		Properties: tiledProperties{
+			{"minx", "int", w.Min.X},
+			{"miny", "int", w.Min.Y},
+			{"title", "string", w.Meta.Title},
+			{"author", "string", w.Meta.Author},
+			{"music", "string", w.Meta.Music},
+			{"tileset", "string", w.Meta.Tileset},
+			{"timelimit", "int", w.Meta.TimeLimit},
+			{"lives", "int", w.Meta.Lives},
+			{"parallax", "string", strings.Join(w.Meta.Parallax, ",")},
+		},
+	}
+
+	b, err := json.MarshalIndent(&m, "", " ")
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(append(b, '\n'))
+	return err
+}
+
+// DecodeTiled reads a Tiled JSON map written by EncodeTiled or made in Tiled
+// following the same layout.
+func DecodeTiled(r io.Reader) (*World, error) {
+	var m tiledMap
+	err := json.NewDecoder(r).Decode(&m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Type != "map" {
+		return nil, fmt.Errorf("tiled: not a map")
+	}
+	if m.Orientation != "orthogonal" || m.Infinite {
+		return nil, fmt.Errorf("tiled: only finite orthogonal maps are supported")
+	}
+	if m.TileWidth != TileSize || m.TileHeight != TileSize {
+		return nil, fmt.Errorf("tiled: tiles must be %dx%d", TileSize, TileSize)
+	}
+	if m.Width <= 0 || m.Height <= 0 {
+		return nil, fmt.Errorf("tiled: map is empty")
+	}
+
+	var terrain, shapes *tiledTileset
+	for i := range m.Tilesets {
+		ts := &m.Tilesets[i]
+		if ts.Source != "" {
+			return nil, fmt.Errorf("tiled: tileset %q must be embedded in the map", ts.Source)
+		}
+		switch ts.Name {
+		case "terrain":
+			terrain = ts
+		case "shapes":
+			shapes = ts
+		}
+	}
+	if terrain == nil || shapes == nil {
+		return nil, fmt.Errorf("tiled: map needs the terrain and shapes tilesets")
+	}
+	tileset := func(ts *tiledTileset, gid uint32) (int, error) {
+		gid &^= tiledFlipped
+		if gid < ts.FirstGID || gid >= ts.FirstGID+uint32(ts.TileCount) {
+			return 0, fmt.Errorf("tiled: tile %d is not in the %s tileset", gid, ts.Name)
+		}
+		return int(gid - ts.FirstGID), nil
+	}
+
+	var objects *tiledLayer
+	var tiles, solid []uint32
+	for i := range m.Layers {
+		l := &m.Layers[i]
+		switch l.Name {
+		case "Tiles", "Solid":
+			if l.Type != "tilelayer" || l.Width != m.Width || l.Height != m.Height || int64(len(l.Data)) != m.Width*m.Height {
+				return nil, fmt.Errorf("tiled: layer %q must be a tile layer the size of the map", l.Name)
+			}
+			if l.Name == "Tiles" {
+				tiles = l.Data
+			} else {
+				solid = l.Data
+			}
+		case "Objects":
+			if l.Type != "objectgroup" {
+				return nil, fmt.Errorf("tiled: layer %q must be an object layer", l.Name)
+			}
+			objects = l
+		}
+	}
+	if objects == nil {
+		objects = &tiledLayer{}
+	}
+
+	// map coordinates start at 0.
+	var min Coord
+	if min.X, err = m.Properties.getInt("map", "minx"); err != nil {
+		return nil, err
+	}
+	if min.Y, err = m.Properties.getInt("map", "miny"); err != nil {
+		return nil, err
+	}
+	toWorld := func(c Coord) Coord {
+		return c.Add(min)
+	}
+
+	w := &World{Min: toWorld(Coord{0, 0}), Max: toWorld(Coord{m.Width - 1, m.Height - 1})}
+	if w.Meta.Title, err = m.Properties.getString("map", "title"); err != nil {
+		return nil, err
+	}
+	if w.Meta.Author, err = m.Properties.getString("map", "author"); err != nil {
+		return nil, err
+	}
+	if w.Meta.Music, err = m.Properties.getString("map", "music"); err != nil {
+		return nil, err
+	}
+	if w.Meta.Tileset, err = m.Properties.getString("map", "tileset"); err != nil {
+		return nil, err
+	}
+	parallax, err := m.Properties.getString("map", "parallax")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(parallax, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			w.Meta.Parallax = append(w.Meta.Parallax, name)
+		}
+	}
+	limit, err := m.Properties.getInt("map", "timelimit")
+	if err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("tiled: map timelimit cannot be negative")
+	}
+	w.Meta.TimeLimit = uint64(limit)
+	if w.Meta.Lives, err = m.Properties.getInt("map", "lives"); err != nil {
+		return nil, err
+	}
+	for i := range objects.Objects {
+		if o := &objects.Objects[i]; o.Name == "spawn" {
+			w.Meta.Spawn = Coord{
+				int64(math.Floor(o.X*PixelSize+0.5)) + w.Min.X*TileSize*PixelSize,
+				int64(math.Floor(o.Y*PixelSize+0.5)) + w.Min.Y*TileSize*PixelSize,
+			}
+		}
+	}
+	w.Tiles = make([]WorldTile, m.Width*m.Height)
+	for y := w.Min.Y; y <= w.Max.Y; y++ {
+		for x := w.Min.X; x <= w.Max.X; x++ {
+			i, _ := w.index(x, y)
+			j := (y-w.Min.Y)*m.Width + (x - w.Min.X)
+			t := &w.Tiles[i]
+			if tiles != nil && tiles[j] != 0 {
+				if t.Tile, err = tileset(terrain, tiles[j]); err != nil {
+					return nil, err
+				}
+			}
+			if solid != nil && solid[j] != 0 {
+				s, err := tileset(shapes, solid[j])
+				if err != nil {
+					return nil, err
+				}
+				t.Solid = s < TileShape_count
+				t.Shape = TileShape(s % TileShape_count)
+			}
+		}
+	}
+
+	inside := func(o *tiledObject, c Coord) error {
+		if c.X < w.Min.X || c.Y < w.Min.Y || c.X > w.Max.X || c.Y > w.Max.Y {
+			return fmt.Errorf("tiled: object %d is outside the map", o.ID)
+		}
+		return nil
+	}
+
+	entities := make(map[int]int)
+	triggers := make(map[int]int)
+	for i := range objects.Objects {
+		o := &objects.Objects[i]
+		switch class := o.class(); class {
+		case "special":
+			special, err := lookupName("special tile", specialTile_names[:], o.Name)
+			if err != nil {
+				return nil, err
+			}
+			min, max := o.tiles()
+			min, max = toWorld(min), toWorld(max)
+			if err = inside(o, min); err != nil {
+				return nil, err
+			}
+			if err = inside(o, max); err != nil {
+				return nil, err
+			}
+			for x := min.X; x <= max.X; x++ {
+				for y := min.Y; y <= max.Y; y++ {
+					i, _ := w.index(x, y)
+					w.Tiles[i].SpecialTile = SpecialTile(special)
+				}
+			}
+
+		case "trigger":
+			min, max := o.tiles()
+			event, err := o.propString("event")
+			if err != nil {
+				return nil, err
+			}
+			kind, err := lookupName("trigger event", triggerEvent_names[:], event)
+			if err != nil {
+				return nil, err
+			}
+			repeat, err := o.propInt("repeat")
+			if err != nil {
+				return nil, err
+			}
+			if repeat < 0 {
+				return nil, fmt.Errorf("tiled: object %d repeat cannot be negative", o.ID)
+			}
+			triggers[o.ID] = len(w.Triggers)
+			w.Triggers = append(w.Triggers, Trigger{
+				Min:    toWorld(min),
+				Max:    toWorld(max),
+				Event:  TriggerEvent(kind),
+				Repeat: uint64(repeat),
+			})
+
+		default:
+			kind, err := lookupName("entity kind", entityKind_names[:], class)
+			if class == "" || err != nil {
+				// actions are read once all the triggers are known.
+				continue
+			}
+			min, max := o.tiles()
+			e := Entity{
+				Kind: EntityKind(kind),
+				Size: max.Sub(min).Add(Coord{1, 1}),
+				Path: []Coord{toWorld(min)},
+			}
+			path, err := o.propString("path")
+			if err != nil {
+				return nil, err
+			}
+			rest, err := parseTiles(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range rest {
+				e.Path = append(e.Path, toWorld(c))
+			}
+			e.Speed = EntitySpeed
+			if _, ok := o.Properties.get("speed"); ok {
+				if e.Speed, err = o.propInt("speed"); err != nil {
+					return nil, err
+				}
+			}
+			tile, err := o.propInt("tile")
+			if err != nil {
+				return nil, err
+			}
+			e.Tile = int(tile)
+			sw, err := o.propString("switch")
+			if err != nil {
+				return nil, err
+			}
+			if sw != "" {
+				c, err := parseTiles(sw)
+				if err != nil {
+					return nil, err
+				}
+				if len(c) != 1 {
+					return nil, fmt.Errorf("tiled: object %d switch must be one tile", o.ID)
+				}
+				e.Switch = toWorld(c[0])
+			}
+			if e.stuck() {
+				return nil, fmt.Errorf("tiled: object %d has a path that does not go anywhere", o.ID)
+			}
+			entities[o.ID] = len(w.Entities)
+			w.Entities = append(w.Entities, e)
+		}
+	}
+
+	for i := range objects.Objects {
+		o := &objects.Objects[i]
+		kind, err := lookupName("action", actionKind_names[:], o.class())
+		if o.class() == "" || err != nil {
+			continue
+		}
+		id, err := o.propInt("trigger")
+		if err != nil {
+			return nil, err
+		}
+		t, ok := triggers[int(id)]
+		if !ok {
+			return nil, fmt.Errorf("tiled: object %d is an action without a trigger", o.ID)
+		}
+		a := Action{
+			Kind: ActionKind(kind),
+			At:   toWorld(o.tile()),
+		}
+		if a.Kind == Action_OpenDoor {
+			id, err := o.propInt("door")
+			if err != nil {
+				return nil, err
+			}
+			door, ok := entities[int(id)]
+			if !ok {
+				door = -1
+			}
+			a.Door = door
+		}
+		if a.Text, err = o.propString("text"); err != nil {
+			return nil, err
+		}
+		w.Triggers[t].Actions = append(w.Triggers[t].Actions, a)
+	}
+
+	if err = w.Meta.check(); err != nil {
+		return nil, err
+	}
+	n, err := tilesetSize(w.Meta.Tileset)
+	if err != nil {
+		return nil, fmt.Errorf("tiled: tileset %q cannot be read: %v", w.Meta.Tileset, err)
+	}
+	for x := w.Min.X; x <= w.Max.X; x++ {
+		for y := w.Min.Y; y <= w.Max.Y; y++ {
+			if err = w.at(Coord{x, y}).check(n); err != nil {
+				return nil, fmt.Errorf("tiled: tile %d,%d: %v", x, y, err)
+			}
+		}
+	}
+	return w, nil
+}
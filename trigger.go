@@ -0,0 +1,155 @@
+package main
+
+import (
+	"image/color"
+)
+
+type TriggerEvent int
+
+const (
+	Trigger_Enter   TriggerEvent = iota // a man is in the region
+	Trigger_AllMans                     // every living man is in the region
+	Trigger_Killed                      // a unit dies in the region
+	Trigger_Switch                      // a switch tile in the region is stood on
+	TriggerEvent_count
+)
+
+var triggerEvent_names [TriggerEvent_count]string = [...]string{
+	Trigger_Enter:   "enter",
+	Trigger_AllMans: "all mans",
+	Trigger_Killed:  "killed",
+	Trigger_Switch:  "switch",
+}
+
+type ActionKind int
+
+const (
+	Action_OpenDoor   ActionKind = iota // opens door entity Door
+	Action_SpawnUnit                    // spawns a grub standing in tile At
+	Action_Floater                      // shows Text at tile At
+	Action_Checkpoint                   // moves every man's checkpoint to tile At
+	ActionKind_count
+)
+
+var actionKind_names [ActionKind_count]string = [...]string{
+	Action_OpenDoor:   "open door",
+	Action_SpawnUnit:  "spawn unit",
+	Action_Floater:    "floater",
+	Action_Checkpoint: "checkpoint",
+}
+
+type Action struct {
+	Kind ActionKind
+	Door int   // index in World.Entities
+	At   Coord // tile coordinates
+	Text string
+}
+
+// Trigger runs its actions when Event happens in the region of tiles from Min
+// to Max. It only fires once unless Repeat is set, in which case it can fire
+// again that many ticks later.
+type Trigger struct {
+	Min, Max Coord
+	Event    TriggerEvent
+	Repeat   uint64
+	Actions  []Action
+}
+
+func (t *Trigger) contains(pos Coord) bool {
+	tile := pos.Floor(TileSize * PixelSize)
+	x, y := tile.X/TileSize/PixelSize, tile.Y/TileSize/PixelSize
+	return x >= t.Min.X && x <= t.Max.X && y >= t.Min.Y && y <= t.Max.Y
+}
+
+// tileBottom returns the bottom middle of the tile at x, y, where a unit
+// standing in the tile would have its position.
+func tileBottom(c Coord) Coord {
+	return Coord{c.X*TileSize*PixelSize + TileSize*PixelSize/2, (c.Y+1)*TileSize*PixelSize - 1}
+}
+
+// UpdateTriggers fires the triggers whose events happened this tick.
+func (state *State) UpdateTriggers() {
+	killed, pressed := state.killed, state.pressed
+	state.killed, state.pressed = nil, nil
+
+	for i := range state.world.Triggers {
+		t := &state.world.Triggers[i]
+		if fired, ok := state.Fired[i]; ok && (t.Repeat == 0 || state.Tick < fired+t.Repeat) {
+			continue
+		}
+
+		happened := false
+		switch t.Event {
+		case Trigger_Enter, Trigger_AllMans:
+			alive, inside := 0, 0
+			for j := range state.Mans {
+				u := &state.Mans[j]
+				if u.Health <= 0 {
+					continue
+				}
+				alive++
+				if t.contains(u.Position.Sub(Coord{0, 1})) {
+					inside++
+				}
+			}
+			if t.Event == Trigger_Enter {
+				happened = inside != 0
+			} else {
+				happened = alive != 0 && inside == alive
+			}
+		case Trigger_Killed:
+			for _, pos := range killed {
+				happened = happened || t.contains(pos)
+			}
+		case Trigger_Switch:
+			for _, tile := range pressed {
+				happened = happened || t.contains(Coord{tile.X * TileSize * PixelSize, tile.Y * TileSize * PixelSize})
+			}
+		}
+		if !happened {
+			continue
+		}
+
+		if state.Fired == nil {
+			state.Fired = make(map[int]uint64)
+		}
+		state.Fired[i] = state.Tick
+		for _, a := range t.Actions {
+			state.Act(a)
+		}
+	}
+}
+
+func (state *State) Act(a Action) {
+	switch a.Kind {
+	case Action_OpenDoor:
+		state.OpenDoor(a.Door)
+
+	case Action_SpawnUnit:
+		u := &Unit{UnitData: &Grub{}}
+		u.Health = u.MaxHealth(state, u)
+		u.Position = tileBottom(a.At)
+		state.Units[state.NextUnit] = u
+		state.NextUnit++
+		state.UnitMoved(u)
+
+	case Action_Floater:
+		pos := tileBottom(a.At)
+		state.Floaters = append(state.Floaters, Floater{
+			S:  a.Text,
+			Fg: color.RGBA{255, 255, 255, 255},
+			Bg: color.RGBA{0, 0, 0, 255},
+			X:  pos.X,
+			Y:  pos.Y,
+			T:  state.Tick,
+		})
+
+	case Action_Checkpoint:
+		pos := tileBottom(a.At)
+		pos.Y++
+		state.SpawnPoint = pos
+		for i := range state.Mans {
+			*state.Mans[i].UnitData.(Man).Checkpoint() = pos
+		}
+	}
+}
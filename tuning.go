@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"github.com/Rnoadm/wdvn/res"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// tuning holds the gameplay values that can be changed without recompiling.
+// Distances and speeds are in the same units as Coord, times are in ticks.
+type tuning struct {
+	WhipTimeMin    uint64
+	WhipTimeMax    uint64
+	WhipDamageMin  int64
+	WhipDamageMax  int64
+	WhipSpeedMin   int64
+	WhipSpeedMax   int64
+	WhipDistance   int64
+	WhipReel       int64
+	LemonSpeed     int64
+	LemonTime      uint64
+	LemonLife      uint64
+	LemonBounce    int64
+	LemonAmmo      int64
+	LemonAmmoMax   int64
+	GrubLemons     int64
+	VacuumHurt     int64
+	VacuumSpeed    int64
+	VacuumDistance int64
+	VacuumSuck     int64
+	VacuumHold     int64
+	PortalDistance int64
+	DensityMin     int64
+	DensityMax     int64
+	PoundDensity   int64
+	PoundSpeed     int64
+	PoundRadius    int64
+	ShieldTime     uint64
+	ShieldCooldown uint64
+	ShieldAbsorb   int64
+	ShieldRadius   int64
+	CannonTime     uint64
+}
+
+var Tuning = tuning{
+	WhipTimeMin:    0.2 * TicksPerSecond,
+	WhipTimeMax:    1.5 * TicksPerSecond,
+	WhipDamageMin:  10,
+	WhipDamageMax:  5000,
+	WhipSpeedMin:   200 * PixelSize,
+	WhipSpeedMax:   1500 * PixelSize,
+	WhipDistance:   10 * TileSize * PixelSize,
+	WhipReel:       5 * TileSize * PixelSize,
+	LemonSpeed:     1000 * PixelSize,
+	LemonTime:      0.3 * TicksPerSecond,
+	LemonLife:      5 * TicksPerSecond,
+	LemonBounce:    60,
+	LemonAmmo:      10,
+	LemonAmmoMax:   30,
+	GrubLemons:     5,
+	VacuumHurt:     TicksPerSecond / 5,
+	VacuumSpeed:    100 * PixelSize,
+	VacuumDistance: 1000 * PixelSize,
+	VacuumSuck:     20,
+	VacuumHold:     3,
+	PortalDistance: 20 * TileSize * PixelSize,
+	DensityMin:     -Gravity,
+	DensityMax:     4 * Gravity,
+	PoundDensity:   2 * Gravity,
+	PoundSpeed:     500 * PixelSize,
+	PoundRadius:    4 * TileSize * PixelSize,
+	ShieldTime:     3 * TicksPerSecond,
+	ShieldCooldown: 10 * TicksPerSecond,
+	ShieldAbsorb:   3000,
+	ShieldRadius:   3 * TileSize * PixelSize,
+	CannonTime:     1 * TicksPerSecond,
+}
+
+// tuningPacket is what the server sends to clients so they simulate and draw
+// with the same values and know the rules.
+type tuningPacket struct {
+	Mans   [res.Man_count]manData
+	Tuning tuning
+	Rules  rules
+}
+
+// LoadTuning reads a JSON tuning file. The file has the fields of tuning at
+// the top level and a "Mans" object keyed by man name ("Whip", "Density", ...)
+// holding the fields of manData. Anything not in the file keeps its current
+// value.
+func LoadTuning(r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	t := Tuning
+	err = json.Unmarshal(b, &t)
+	if err != nil {
+		return err
+	}
+
+	var file struct {
+		Mans map[string]json.RawMessage
+	}
+	err = json.Unmarshal(b, &file)
+	if err != nil {
+		return err
+	}
+
+	mans := ManData
+	for name, raw := range file.Mans {
+		i, ok := res.Man_value[name]
+		if !ok || res.Man(i) >= res.Man_count {
+			return fmt.Errorf("tuning: unknown man %q", name)
+		}
+		err = json.Unmarshal(raw, &mans[i])
+		if err != nil {
+			return fmt.Errorf("tuning: man %s: %v", name, err)
+		}
+	}
+
+	err = checkTuning(&mans, &t)
+	if err != nil {
+		return err
+	}
+
+	ManData, Tuning = mans, t
+	return nil
+}
+
+// DumpTuning writes the current tuning in the format read by LoadTuning.
+func DumpTuning(w io.Writer) error {
+	mans := make(map[string]manData)
+	for i, d := range ManData {
+		mans[res.Man(i).String()] = d
+	}
+
+	b, err := json.MarshalIndent(struct {
+		tuning
+		Mans map[string]manData
+	}{Tuning, mans}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func EncodeTuning() []byte {
+	return Encode(&tuningPacket{
+		Mans:   ManData,
+		Tuning: Tuning,
+		Rules:  Rules,
+	})
+}
+
+// ApplyTuning replaces the current tuning with one sent by the server.
+func ApplyTuning(b []byte) error {
+	var t tuningPacket
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&t)
+	if err != nil {
+		return err
+	}
+
+	err = checkTuning(&t.Mans, &t.Tuning)
+	if err != nil {
+		return err
+	}
+	err = checkRules(&t.Rules)
+	if err != nil {
+		return err
+	}
+
+	tuningLock.Lock()
+	defer tuningLock.Unlock()
+
+	ManData, Tuning, Rules = t.Mans, t.Tuning, t.Rules
+	graphicsTuning()
+	return nil
+}
+
+// CheckTuning makes sure the current tuning will not crash the game.
+func CheckTuning() error {
+	return checkTuning(&ManData, &Tuning)
+}
+
+func checkTuning(mans *[res.Man_count]manData, t *tuning) error {
+	for i, d := range mans {
+		man := res.Man(i)
+		if d.SizeCrouch.X <= 0 || d.SizeCrouch.Y <= 0 || d.Size.X < d.SizeCrouch.X || d.Size.Y < d.SizeCrouch.Y {
+			return fmt.Errorf("tuning: %v size must be positive and no smaller than crouch size", man)
+		}
+		if d.MaxHealth <= 0 {
+			return fmt.Errorf("tuning: %v max health must be positive", man)
+		}
+		if d.Mass <= 0 || d.MassCrouch <= 0 {
+			return fmt.Errorf("tuning: %v mass must be positive", man)
+		}
+		if d.MoveSpeed < 0 || d.MoveSpeedCrouch < 0 || d.MoveSpeedAir < 0 || d.MoveSpeedAirCrouch < 0 || d.JumpSpeed < 0 {
+			return fmt.Errorf("tuning: %v speeds cannot be negative", man)
+		}
+
+		for j, size := range [...]Coord{d.Size, d.SizeCrouch} {
+			c, err := png.DecodeConfig(strings.NewReader(manspritessrc[i][j]))
+			if err != nil {
+				return err
+			}
+			if int64(c.Width) != size.X/PixelSize || int64(c.Height) != size.Y/PixelSize {
+				return fmt.Errorf("tuning: %v size %dx%d does not match sprite size %dx%d", man, size.X/PixelSize, size.Y/PixelSize, c.Width, c.Height)
+			}
+		}
+	}
+
+	if t.WhipTimeMin >= t.WhipTimeMax {
+		return fmt.Errorf("tuning: WhipTimeMin must be less than WhipTimeMax")
+	}
+	if t.WhipDistance <= 0 || t.VacuumDistance <= 0 || t.PortalDistance <= 0 || t.PoundRadius <= 0 || t.ShieldRadius <= 0 {
+		return fmt.Errorf("tuning: distances must be positive")
+	}
+	if t.WhipDamageMin < 0 || t.WhipDamageMin > t.WhipDamageMax {
+		return fmt.Errorf("tuning: whip damage must be a non-negative range")
+	}
+	if t.WhipSpeedMin < 0 || t.WhipSpeedMin > t.WhipSpeedMax {
+		return fmt.Errorf("tuning: whip speed must be a non-negative range")
+	}
+	if t.LemonSpeed < 0 || t.VacuumSpeed < 0 || t.WhipReel < 0 || t.PoundSpeed < 0 {
+		return fmt.Errorf("tuning: speeds cannot be negative")
+	}
+	if t.DensityMin > 0 || t.DensityMax < 0 {
+		return fmt.Errorf("tuning: DensityMin and DensityMax must be on either side of zero")
+	}
+	if t.VacuumHurt <= 0 || t.VacuumSuck <= 0 || t.VacuumHold <= 0 {
+		return fmt.Errorf("tuning: VacuumHurt, VacuumSuck and VacuumHold must be positive")
+	}
+	if t.ShieldTime == 0 || t.ShieldTime > t.ShieldCooldown || t.ShieldAbsorb <= 0 {
+		return fmt.Errorf("tuning: ShieldTime and ShieldAbsorb must be positive and the shield cannot last longer than ShieldCooldown")
+	}
+	if t.LemonLife == 0 {
+		return fmt.Errorf("tuning: LemonLife must be positive")
+	}
+	if t.LemonBounce < 0 || t.LemonBounce > 100 {
+		return fmt.Errorf("tuning: LemonBounce must be a percentage")
+	}
+	if t.LemonAmmo < 0 || t.LemonAmmo > t.LemonAmmoMax || t.GrubLemons < 0 {
+		return fmt.Errorf("tuning: lemon ammo must be a non-negative range")
+	}
+
+	return nil
+}
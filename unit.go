@@ -1,10 +1,11 @@
 package main
 
 import (
-	"github.com/Rnoadm/wdvn/res"
+	"fmt"
 	"github.com/dustin/go-humanize"
 	"image"
 	"image/color"
+	"math"
 	"math/rand"
 )
 
@@ -36,10 +37,34 @@ func (u *Unit) OnGround(state *State) (bool, SpecialTile) {
 	return tr.End == u.Position, tr.Special
 }
 
-func (u *Unit) Hurt(state *State, by *Unit, amount int64) {
-	if amount <= 0 || u.Health <= 0 {
+// Hurt deals amount damage to u after the server's rules and any shield have
+// had their say. by is the unit responsible, if any.
+func (u *Unit) Hurt(state *State, by *Unit, amount int64, cause DamageCause) {
+	if u.Health <= 0 {
 		return
 	}
+	amount = Rules.Damage(u, by, amount, cause)
+	if amount <= 0 {
+		return
+	}
+	if u.IsMan() {
+		amount = state.Shield(u, amount)
+		if amount <= 0 {
+			return
+		}
+	}
+	u.damage(state, by, amount, cause)
+}
+
+// Kill takes away all of u's health, whatever the rules and shields say.
+func (u *Unit) Kill(state *State, by *Unit, cause DamageCause) {
+	if u.Health <= 0 {
+		return
+	}
+	u.damage(state, by, u.Health, cause)
+}
+
+func (u *Unit) damage(state *State, by *Unit, amount int64, cause DamageCause) {
 	if u.ShowDamage(state, u) {
 		c := color.RGBA{96, 96, 96, 255}
 		if by != nil {
@@ -57,7 +82,13 @@ func (u *Unit) Hurt(state *State, by *Unit, amount int64) {
 	if u.Health < amount {
 		amount = u.Health
 	}
+	credit := state.Blame(u, by)
 	u.Health -= amount
+	if u.Health == 0 {
+		state.killed = append(state.killed, u.Position.Sub(Coord{0, 1}))
+	}
+	state.Record(u, credit, amount, cause)
+	state.Scored(u, credit, credit == by, amount, cause)
 }
 
 func (u *Unit) IsMan() bool {
@@ -65,6 +96,41 @@ func (u *Unit) IsMan() bool {
 	return ok
 }
 
+// UnitRef refers to a man or unit in a State. Unlike a *Unit, it stays valid
+// when the state is encoded or the roster grows.
+type UnitRef struct {
+	Man  int    // slot in State.Mans plus one
+	Unit uint64 // key in State.Units plus one
+}
+
+func (r UnitRef) Zero() bool {
+	return r == UnitRef{}
+}
+
+func (state *State) Ref(u *Unit) UnitRef {
+	for i := range state.Mans {
+		if u == &state.Mans[i] {
+			return UnitRef{Man: i + 1}
+		}
+	}
+	for id, o := range state.Units {
+		if u == o {
+			return UnitRef{Unit: id + 1}
+		}
+	}
+	return UnitRef{}
+}
+
+func (state *State) Deref(r UnitRef) *Unit {
+	if r.Man > 0 && r.Man <= len(state.Mans) {
+		return &state.Mans[r.Man-1]
+	}
+	if r.Unit > 0 {
+		return state.Units[r.Unit-1]
+	}
+	return nil
+}
+
 func (u *Unit) Update(state *State) {
 	if u.Health > 0 {
 		u.UnitData.Update(state, u)
@@ -81,6 +147,10 @@ func (u *Unit) Update(state *State) {
 
 	u.Velocity.X -= u.Velocity.X / Friction
 	u.Velocity.Y -= u.Velocity.Y / Friction
+	if state.InLiquid(u) {
+		u.Velocity.X -= u.Velocity.X / LiquidFriction
+		u.Velocity.Y -= u.Velocity.Y / LiquidFriction
+	}
 
 	u.Velocity.X += u.Acceleration.X
 	u.Velocity.Y += u.Acceleration.Y
@@ -117,6 +187,11 @@ func (u *Unit) Update(state *State) {
 		switch special {
 		case SpecialTile_Bounce:
 			u.Velocity.Y = -100 * Gravity
+		case SpecialTile_Switch:
+			if u.IsMan() && u.Health > 0 {
+				pos := u.Position.Add(Coord{0, 1}).Floor(TileSize * PixelSize)
+				state.PressSwitch(pos.X/TileSize/PixelSize, pos.Y/TileSize/PixelSize)
+			}
 		case SpecialTile_Checkpoint:
 			if m, ok := u.UnitData.(Man); ok && u.Health > 0 {
 				pos := u.Position.Floor(TileSize * PixelSize)
@@ -137,14 +212,20 @@ func (u *Unit) Update(state *State) {
 					break
 				}
 				if pos != *m.Checkpoint() && pos != state.SpawnPoint {
-					count := 0
+					count := 1
 					for i := range state.Mans {
 						if pos == *state.Mans[i].UnitData.(Man).Checkpoint() {
 							count++
 						}
 					}
-					text := [res.Man_count]string{"CHECKPOINT 25%", "CHECKPOINT 50%", "CHECKPOINT 75%", "CHECKPOINT UNLOCKED"}[count]
+					text := "CHECKPOINT UNLOCKED"
+					if count < len(state.Mans) {
+						text = fmt.Sprintf("CHECKPOINT %d%%", count*100/len(state.Mans))
+					}
 					*m.Checkpoint() = pos
+					if s := state.stats(u); s != nil {
+						s.Checkpoints++
+					}
 					state.Floaters = append(state.Floaters, Floater{
 						S:  text,
 						Fg: color.RGBA{255, 255, 255, 255},
@@ -153,7 +234,7 @@ func (u *Unit) Update(state *State) {
 						Y:  pos.Y,
 						T:  state.Tick,
 					})
-					if count == int(res.Man_count-1) {
+					if count == len(state.Mans) {
 						state.SpawnPoint = pos
 					}
 				}
@@ -164,7 +245,7 @@ func (u *Unit) Update(state *State) {
 	tr := state.Trace(u.Position, u.Position.Add(Coord{u.Velocity.X / TicksPerSecond, u.Velocity.Y / TicksPerSecond}), u.Size(state, u), false)
 	collide := tr.CollideWith(state, u)
 
-	if u.Health > 0 && tr.End == u.Position && !u.Velocity.Zero() {
+	if u.Health > 0 && tr.End == u.Position && !u.Velocity.Zero() && !(collide == nil && tr.HitWorld && tr.Side == SideTop && tr.Shape.Sloped()) {
 		stuck := state.Trace(u.Position, u.Position.Add(Coord{u.Velocity.X / TicksPerSecond, 0}), u.Size(state, u), false)
 		collide2 := stuck.CollideWith(state, u)
 
@@ -189,21 +270,37 @@ func (u *Unit) Update(state *State) {
 		stuck := state.Trace(u.Position, u.Position.Add(delta), u.Size(state, u), true)
 		tr.End = stuck.End
 	}
-	if collide == nil && tr.HitWorld {
+	teleported := collide == nil && tr.HitWorld && state.EnterPortal(u, tr)
+	bounced := collide == nil && tr.HitWorld && !teleported && state.BounceLemon(u, tr)
+	slide := false
+	if collide == nil && tr.HitWorld && !teleported && !bounced {
 		switch tr.Special {
-		case SpecialTile_None:
+		case SpecialTile_None, SpecialTile_Fragile, SpecialTile_Switch:
 			switch tr.Side {
 			case SideLeft:
-				u.Hurt(state, nil, u.Velocity.X*u.Mass(state, u)/DamageFactor)
+				u.Hurt(state, nil, u.Velocity.X*u.Mass(state, u)/DamageFactor, DamageFall)
 				u.Velocity.X = 0
 			case SideRight:
-				u.Hurt(state, nil, -u.Velocity.X*u.Mass(state, u)/DamageFactor)
+				u.Hurt(state, nil, -u.Velocity.X*u.Mass(state, u)/DamageFactor, DamageFall)
 				u.Velocity.X = 0
 			case SideTop:
-				u.Hurt(state, nil, u.Velocity.Y*u.Mass(state, u)/DamageFactor)
-				u.Velocity.Y = 0
+				if tr.Shape.Sloped() {
+					// only the part of the velocity going into the slope is stopped
+					n := tr.Shape.Normal()
+					if dot := u.Velocity.X*n.X + u.Velocity.Y*n.Y; dot < 0 {
+						u.Hurt(state, nil, int64(float64(-dot)/math.Sqrt(float64(n.LengthSquared())))*u.Mass(state, u)/DamageFactor, DamageFall)
+						u.Velocity.X -= dot * n.X / n.LengthSquared()
+						u.Velocity.Y -= dot * n.Y / n.LengthSquared()
+					}
+					slide = true
+				} else {
+					if !state.GroundPound(u, u.Velocity.Y) {
+						u.Hurt(state, nil, u.Velocity.Y*u.Mass(state, u)/DamageFactor, DamageFall)
+					}
+					u.Velocity.Y = 0
+				}
 			case SideBottom:
-				u.Hurt(state, nil, -u.Velocity.Y*u.Mass(state, u)/DamageFactor)
+				u.Hurt(state, nil, -u.Velocity.Y*u.Mass(state, u)/DamageFactor, DamageFall)
 				u.Velocity.Y = 0
 			}
 		case SpecialTile_Bounce:
@@ -223,8 +320,36 @@ func (u *Unit) Update(state *State) {
 			panic("unimplemented special tile type: " + specialTile_names[tr.Special])
 		}
 	}
+	if slide {
+		// use the rest of the movement to follow the slope, lifted off the
+		// surface a little so rounding doesn't catch on it.
+		rest := Coord{u.Velocity.X / TicksPerSecond, u.Velocity.Y / TicksPerSecond}.Sub(tr.End.Sub(u.Position))
+		n := tr.Shape.Normal()
+		dot := rest.X*n.X + rest.Y*n.Y
+		rest.X -= dot * n.X / n.LengthSquared()
+		rest.Y -= dot*n.Y/n.LengthSquared() + 1
+		follow := state.Trace(tr.End, tr.End.Add(rest), u.Size(state, u), false)
+		if follow.CollideWith(state, u) == nil {
+			tr.End = follow.End
+		}
+	}
+	if (onGround || slide) && u.Acceleration.Y >= 0 && !teleported {
+		// stay on the ground when walking down a slope
+		drop := u.Velocity.X / TicksPerSecond
+		if drop < 0 {
+			drop = -drop
+		}
+		snap := state.Trace(tr.End, tr.End.Add(Coord{0, drop + PixelSize}), u.Size(state, u), true)
+		if snap.HitWorld && snap.Shape.Sloped() {
+			n := snap.Shape.Normal()
+			if dot := u.Velocity.X*n.X + u.Velocity.Y*n.Y; dot < 0 || dot*dot <= MinimumVelocity*MinimumVelocity*n.LengthSquared() {
+				tr.End = snap.End
+			}
+		}
+	}
 	u.Position = tr.End
 	if u.Health > 0 && collide != nil {
+		state.CannonHit(u, collide)
 		if u.IsMan() != collide.IsMan() {
 			u.Velocity.X, u.Velocity.Y = u.Velocity.X*2, u.Velocity.Y*2
 			collide.Velocity.X, collide.Velocity.Y = collide.Velocity.X*2, collide.Velocity.Y*2
@@ -253,8 +378,8 @@ func (u *Unit) Update(state *State) {
 			if v2 < 0 {
 				v2 = -v2
 			}
-			u.Hurt(state, nil, v1*collide.Mass(state, u)/DamageFactor)
-			collide.Hurt(state, nil, v2*u.Mass(state, u)/DamageFactor)
+			u.Hurt(state, collide, v1*collide.Mass(state, u)/DamageFactor, DamageCollision)
+			collide.Hurt(state, u, v2*u.Mass(state, u)/DamageFactor, DamageCollision)
 		case SideTop, SideBottom:
 			v1, v2 := weightedSwap(u.Velocity.Y, collide.Velocity.Y)
 			u.Velocity.Y, collide.Velocity.Y = v1, v2
@@ -265,11 +390,16 @@ func (u *Unit) Update(state *State) {
 			if v2 < 0 {
 				v2 = -v2
 			}
-			u.Hurt(state, nil, v1*collide.Mass(state, u)/DamageFactor)
-			collide.Hurt(state, nil, v2*u.Mass(state, u)/DamageFactor)
+			// whichever unit is underneath is crushed.
+			uCause, collideCause := DamageCollision, DamageCrush
+			if tr.Side == SideBottom {
+				uCause, collideCause = collideCause, uCause
+			}
+			u.Hurt(state, collide, v1*collide.Mass(state, u)/DamageFactor, uCause)
+			collide.Hurt(state, u, v2*u.Mass(state, u)/DamageFactor, collideCause)
 		}
 	}
 	if pos := u.Position.Floor(PixelSize * TileSize); state.world.Outside(pos.X/TileSize/PixelSize, pos.Y/TileSize/PixelSize) > 100 {
-		u.Hurt(state, nil, u.Health)
+		u.Kill(state, nil, DamageOutOfWorld)
 	}
 }
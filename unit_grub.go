@@ -47,9 +47,18 @@ func (g *Grub) Sprite(state *State, u *Unit) *image.RGBA {
 func (g *Grub) Mass(state *State, u *Unit) int64 {
 	return 200
 }
-func (g *Grub) ShowDamage() bool {
+func (g *Grub) Gravity(state *State, u *Unit) int64 {
+	return Gravity
+}
+func (g *Grub) Size(state *State, u *Unit) Coord {
+	return Coord{30 * PixelSize, 14 * PixelSize}
+}
+func (g *Grub) MaxHealth(state *State, u *Unit) int64 {
+	return 1000
+}
+func (g *Grub) ShowDamage(state *State, u *Unit) bool {
 	return true
 }
-func (g *Grub) Color() color.RGBA {
+func (g *Grub) Color(state *State, u *Unit) color.RGBA {
 	return color.RGBA{0, 0, 0, 255}
 }
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Rnoadm/wdvn/res"
+	"image/png"
+	"sort"
+	"strings"
+)
+
+const (
+	ValidateSpawns = 20                 // times each man is spawned to make sure there is room
+	ValidateTicks  = 5 * TicksPerSecond // longest a single walk or jump is followed
+)
+
+// Problem is something ValidateLevel found wrong with a level.
+type Problem struct {
+	Tile Coord
+	Text string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("tile %d,%d: %s", p.Tile.X, p.Tile.Y, p.Text)
+}
+
+// tileAt returns the tile pos is in.
+func tileAt(pos Coord) Coord {
+	pos = pos.Floor(TileSize * PixelSize)
+	return Coord{pos.X / TileSize / PixelSize, pos.Y / TileSize / PixelSize}
+}
+
+// tilesetSize returns how many tiles are in the named tileset.
+func tilesetSize(name string) (int, error) {
+	if name == "" {
+		name = DefaultTileset
+	}
+	c, err := png.DecodeConfig(strings.NewReader(tilesetsrc[name]))
+	if err != nil {
+		return 0, err
+	}
+	return c.Width / TileSize, nil
+}
+
+// ValidateLevel looks for things that make w unplayable: tiles that cannot
+// be drawn or cannot be what they are, a spawn point mans cannot spawn at,
+// and checkpoints that cannot be reached by walking and jumping from the
+// spawn point.
+func ValidateLevel(w *World) []Problem {
+	var problems []Problem
+	report := func(tile Coord, format string, args ...interface{}) {
+		problems = append(problems, Problem{tile, fmt.Sprintf(format, args...)})
+	}
+
+	// without the tileset, there is no telling which tiles are in it.
+	tiles, err := tilesetSize(w.Meta.Tileset)
+	if err != nil {
+		report(Coord{}, "tileset %q cannot be read: %v", w.Meta.Tileset, err)
+	}
+	for x := w.Min.X; err == nil && x <= w.Max.X; x++ {
+		for y := w.Min.Y; y <= w.Max.Y; y++ {
+			if err := w.at(Coord{x, y}).check(tiles); err != nil {
+				report(Coord{x, y}, "%v", err)
+			}
+		}
+	}
+	for i, e := range w.Entities {
+		if len(e.Path) == 0 {
+			report(Coord{}, "%s %d has no path", entityKind_names[e.Kind], i)
+			continue
+		}
+		if e.stuck() {
+			report(e.Path[0], "%s %d has a path that does not go anywhere", entityKind_names[e.Kind], i)
+		}
+		if err == nil && (e.Tile < 0 || e.Tile >= tiles) {
+			report(e.Path[0], "%s %d uses tile index %d, which is not in the tileset", entityKind_names[e.Kind], i, e.Tile)
+		}
+	}
+
+	spawn := tileAt(w.Meta.Spawn.Sub(Coord{0, 1}))
+	if w.Outside(spawn.X, spawn.Y) > 100 {
+		report(spawn, "spawn point is so far outside the level that mans die there")
+	} else if w.Solid(spawn.X, spawn.Y) && w.Shape(spawn.X, spawn.Y) == TileShape_Full {
+		report(spawn, "spawn point is inside solid ground")
+	}
+
+	// entities and triggers depend on what the mans do, so they are left
+	// out and only the tiles are explored.
+	world := *w
+	world.Entities, world.Triggers, world.Meta.TimeLimit = nil, nil, 0
+	world.rendered = nil
+
+	checkpoints := make(map[Coord]bool)
+	for x := w.Min.X; x <= w.Max.X; x++ {
+		for y := w.Min.Y; y <= w.Max.Y; y++ {
+			if w.Special(x, y) == SpecialTile_Checkpoint {
+				checkpoints[Coord{x, y}] = false
+			}
+		}
+	}
+	left := len(checkpoints)
+	reached := func(tile Coord) {
+		// the same search as the one that sets the checkpoint.
+		for x := int64(-3); x <= 3; x++ {
+			for y := int64(-3); y <= 3; y++ {
+				c := tile.Add(Coord{x, y})
+				if r, ok := checkpoints[c]; ok && !r {
+					checkpoints[c] = true
+					left--
+				}
+			}
+		}
+	}
+
+	type movement struct {
+		move, air, jump, gravity int64
+		size                     Coord
+	}
+	explored := make(map[movement]bool)
+
+	for man := res.Man(0); man < res.Man_count; man++ {
+		state := NewState(&world)
+		state.Mans = state.Mans[man : man+1]
+		state.Scores = state.Scores[man : man+1]
+		state.Stats = state.Stats[man : man+1]
+		u := &state.Mans[0]
+
+		failed := 0
+		for i := 0; i < ValidateSpawns; i++ {
+			// a man respawns dead, so it isn't in its own way.
+			u.Health = 0
+			state.FindSpawnPosition(u)
+			if u.Position == state.SpawnPoint {
+				failed++
+			}
+		}
+		if failed != 0 {
+			report(spawn, "%v man found no room to spawn %d out of %d times", man, failed, ValidateSpawns)
+			continue
+		}
+
+		d := ManData[man]
+		m := movement{d.MoveSpeed, d.MoveSpeedAir, d.JumpSpeed, d.Gravity, d.Size}
+		if left == 0 || explored[m] {
+			continue
+		}
+		explored[m] = true
+		if !explore(state, reached, func() bool { return left == 0 }) {
+			report(spawn, "%v man cannot land safely after spawning", man)
+		}
+	}
+
+	var missed []Coord
+	for c, r := range checkpoints {
+		if !r {
+			missed = append(missed, c)
+		}
+	}
+	sort.Sort(coordsByPosition(missed))
+	for _, c := range missed {
+		if !checkpoints[c] {
+			report(c, "checkpoint cannot be reached by walking and jumping from the spawn point")
+			// the rest of the same checkpoint is reported with it.
+			reachedGroup(checkpoints, c)
+		}
+	}
+
+	sort.Stable(problemsByPosition(problems))
+	return problems
+}
+
+// reachedGroup marks every checkpoint tile touching c as reached.
+func reachedGroup(checkpoints map[Coord]bool, c Coord) {
+	if r, ok := checkpoints[c]; !ok || r {
+		return
+	}
+	checkpoints[c] = true
+	for _, n := range [...]Coord{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		reachedGroup(checkpoints, c.Add(n))
+	}
+}
+
+// explore walks and jumps the only man in state around the level from where
+// it spawned, calling reached with the tile under it whenever it stands on a
+// checkpoint. It stops early when done returns true. It returns false if the
+// man never lands safely.
+func explore(state *State, reached func(Coord), done func() bool) bool {
+	u := &state.Mans[0]
+	m := u.UnitData.(Man)
+	run := ManData[m.Man()].MoveSpeed * Friction // fastest a man can walk
+
+	// follow runs the man from pos with velocity v and the input p held
+	// down until it stands somewhere new, and returns where it stands.
+	follow := func(pos, v Coord, p *res.Packet) (Coord, bool) {
+		u.Position, u.Velocity, u.Acceleration = pos, v, Coord{}
+		u.Health = u.MaxHealth(state, u)
+		m.Input(p)
+		from := tileAt(pos.Sub(Coord{0, 1}))
+		airborne := false
+		for t := 0; t < ValidateTicks; t++ {
+			state.Tick++
+			u.Update(state)
+			state.Floaters, state.killed, state.pressed = nil, nil, nil
+			if u.Health <= 0 {
+				return Coord{}, false
+			}
+
+			onGround, special := u.OnGround(state)
+			if !onGround {
+				airborne = true
+				continue
+			}
+			if special == SpecialTile_Checkpoint {
+				reached(tileAt(u.Position))
+			}
+			if tileAt(u.Position.Sub(Coord{0, 1})) != from {
+				return u.Position, true
+			}
+			if airborne || u.Velocity.Zero() && t != 0 {
+				return Coord{}, false
+			}
+		}
+		return Coord{}, false
+	}
+
+	start, ok := follow(u.Position, Coord{}, nil)
+	if !ok {
+		// it may have spawned standing still on the ground already.
+		if onGround, _ := u.OnGround(state); !onGround || u.Health <= 0 {
+			return false
+		}
+		start = u.Position
+	}
+
+	visited := map[Coord]bool{tileAt(start.Sub(Coord{0, 1})): true}
+	queue := []Coord{start}
+	for len(queue) != 0 && !done() {
+		pos := queue[0]
+		queue = queue[1:]
+
+		for _, try := range [...]struct {
+			v    int64
+			dir  int
+			jump bool
+		}{
+			{0, -1, false}, {0, 1, false},
+			{0, -1, true}, {0, 0, true}, {0, 1, true},
+			{-run, -1, false}, {run, 1, false},
+			{-run, -1, true}, {run, 1, true},
+		} {
+			p := &res.Packet{
+				KeyLeft:  Button_released,
+				KeyRight: Button_released,
+				KeyUp:    Button_released,
+			}
+			if try.dir < 0 {
+				p.KeyLeft = Button_pressed
+			} else if try.dir > 0 {
+				p.KeyRight = Button_pressed
+			}
+			if try.jump {
+				p.KeyUp = Button_pressed
+			}
+
+			next, ok := follow(pos, Coord{try.v, 0}, p)
+			if !ok {
+				continue
+			}
+			if tile := tileAt(next.Sub(Coord{0, 1})); !visited[tile] {
+				visited[tile] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return true
+}
+
+type coordsByPosition []Coord
+
+func (c coordsByPosition) Len() int      { return len(c) }
+func (c coordsByPosition) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c coordsByPosition) Less(i, j int) bool {
+	if c[i].Y != c[j].Y {
+		return c[i].Y < c[j].Y
+	}
+	return c[i].X < c[j].X
+}
+
+type problemsByPosition []Problem
+
+func (p problemsByPosition) Len() int      { return len(p) }
+func (p problemsByPosition) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p problemsByPosition) Less(i, j int) bool {
+	return coordsByPosition{p[i].Tile, p[j].Tile}.Less(0, 1)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// Score is how a man is doing in the current versus round.
+type Score struct {
+	KnockOuts int64
+	Damage    int64 // dealt to other mans
+}
+
+func (s Score) Points() int64 {
+	return s.KnockOuts*Rules.KnockOutScore + s.Damage/Rules.DamageScore
+}
+
+// RoundOver reports whether the versus round has ended and the scores are
+// final.
+func (state *State) RoundOver() bool {
+	return Rules.Versus && state.RoundEnd != 0 && state.Tick >= state.RoundEnd
+}
+
+// UpdateRound starts a new versus round when the break after the last one is
+// over.
+func (state *State) UpdateRound() {
+	if !Rules.Versus {
+		return
+	}
+
+	if state.RoundEnd == state.Tick && state.RoundEnd != 0 && len(state.Scores) != 0 {
+		best := state.Standings()[0]
+		u := &state.Mans[best]
+		state.Floaters = append(state.Floaters, Floater{
+			S:  fmt.Sprintf("%v MAN WINS!", u.UnitData.(Man).Man()),
+			Fg: color.RGBA{255, 255, 255, 255},
+			Bg: u.Color(state, u),
+			X:  u.Position.X,
+			Y:  u.Position.Y - u.Size(state, u).Y,
+			T:  state.Tick,
+		})
+	}
+
+	if state.RoundEnd != 0 && state.Tick < state.RoundEnd+Rules.RoundBreak {
+		return
+	}
+
+	state.RoundEnd = state.Tick + Rules.RoundTime
+	if state.world.Meta.TimeLimit != 0 {
+		state.RoundEnd = state.Tick + state.world.Meta.TimeLimit
+	}
+	for i := range state.Scores {
+		state.Scores[i] = Score{}
+	}
+	for i := range state.Mans {
+		u := &state.Mans[i]
+		u.UnitData.(Man).DoRespawn(state, u)
+	}
+}
+
+// Scored gives credit for amount damage dealt to u, which credit is to blame
+// for. Damage a man deals directly scores for them, and a man who dies soon
+// after being hurt by another man counts as a knock-out for the other man.
+func (state *State) Scored(u, credit *Unit, direct bool, amount int64, cause DamageCause) {
+	if !Rules.Versus || state.RoundOver() || !u.IsMan() || credit == nil || credit == u {
+		return
+	}
+
+	slot := state.Ref(credit).Man - 1
+	if slot < 0 || slot >= len(state.Scores) {
+		return
+	}
+	if direct {
+		state.Scores[slot].Damage += amount
+	}
+	if u.Health == 0 {
+		state.Scores[slot].KnockOuts++
+
+		text := "K.O.!"
+		if cause == DamageOutOfWorld {
+			text = "KNOCKED OUT!"
+		}
+		state.Floaters = append(state.Floaters, Floater{
+			S:  text,
+			Fg: color.RGBA{255, 255, 255, 255},
+			Bg: credit.Color(state, credit),
+			X:  u.Position.X,
+			Y:  u.Position.Y - u.Size(state, u).Y,
+			T:  state.Tick,
+		})
+	}
+}
+
+// Standings returns the slots of the mans from most points to least.
+func (state *State) Standings() []int {
+	slots := make([]int, len(state.Scores))
+	for i := range slots {
+		slots[i] = i
+	}
+	sort.Stable(standings{slots, state.Scores})
+	return slots
+}
+
+type standings struct {
+	slots  []int
+	scores []Score
+}
+
+func (s standings) Len() int      { return len(s.slots) }
+func (s standings) Swap(i, j int) { s.slots[i], s.slots[j] = s.slots[j], s.slots[i] }
+func (s standings) Less(i, j int) bool {
+	return s.scores[s.slots[i]].Points() > s.scores[s.slots[j]].Points()
+}
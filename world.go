@@ -3,9 +3,17 @@ package main
 import (
 	"image"
 	"image/draw"
+	"time"
 )
 
-const WorldRenderCacheSize = 64
+const (
+	WorldRenderCacheSize = 64
+
+	// WorldRenderCacheChunks is how many pieces of the render cache, zoomed
+	// or not, are kept before the ones that are not being drawn are thrown
+	// away.
+	WorldRenderCacheChunks = 16
+)
 
 type SpecialTile int
 
@@ -13,6 +21,9 @@ const (
 	SpecialTile_None = iota
 	SpecialTile_Bounce
 	SpecialTile_Checkpoint
+	SpecialTile_Fragile // solid until a DensityMan ground-pounds near it
+	SpecialTile_Liquid  // not solid; slows units and holds up light ones
+	SpecialTile_Switch  // opens doors wired to it when a man stands on it
 	SpecialTile_count
 )
 
@@ -20,19 +31,84 @@ var specialTile_names [SpecialTile_count]string = [...]string{
 	SpecialTile_None:       "none",
 	SpecialTile_Bounce:     "bounce",
 	SpecialTile_Checkpoint: "checkpoint",
+	SpecialTile_Fragile:    "fragile",
+	SpecialTile_Liquid:     "liquid",
+	SpecialTile_Switch:     "switch",
+}
+
+type TileShape int
+
+const (
+	TileShape_Full = iota
+	TileShape_Half
+	TileShape_Slope45Up
+	TileShape_Slope45Down
+	TileShape_Slope22UpLow
+	TileShape_Slope22UpHigh
+	TileShape_Slope22DownHigh
+	TileShape_Slope22DownLow
+	TileShape_count
+)
+
+var tileShape_names [TileShape_count]string = [...]string{
+	TileShape_Full:            "full",
+	TileShape_Half:            "half",
+	TileShape_Slope45Up:       "slope 45 up",
+	TileShape_Slope45Down:     "slope 45 down",
+	TileShape_Slope22UpLow:    "slope 22 up low",
+	TileShape_Slope22UpHigh:   "slope 22 up high",
+	TileShape_Slope22DownHigh: "slope 22 down high",
+	TileShape_Slope22DownLow:  "slope 22 down low",
+}
+
+// distance in pixels from the top of the tile to the solid surface at the left
+// and right edges. the surface is a straight line between the two.
+var tileShape_heights [TileShape_count][2]int64 = [...][2]int64{
+	TileShape_Full:            {0, 0},
+	TileShape_Half:            {TileSize / 2, TileSize / 2},
+	TileShape_Slope45Up:       {TileSize, 0},
+	TileShape_Slope45Down:     {0, TileSize},
+	TileShape_Slope22UpLow:    {TileSize, TileSize / 2},
+	TileShape_Slope22UpHigh:   {TileSize / 2, 0},
+	TileShape_Slope22DownHigh: {0, TileSize / 2},
+	TileShape_Slope22DownLow:  {TileSize / 2, TileSize},
+}
+
+func (s TileShape) Left() int64 {
+	return tileShape_heights[s][0]
+}
+
+func (s TileShape) Right() int64 {
+	return tileShape_heights[s][1]
+}
+
+func (s TileShape) Sloped() bool {
+	return s.Left() != s.Right()
+}
+
+// Normal points away from the solid part of the tile. It is not normalized.
+func (s TileShape) Normal() Coord {
+	return Coord{s.Right() - s.Left(), -TileSize}
 }
 
 type WorldTile struct {
 	Tile  int
 	Solid bool
 	SpecialTile
+	Shape TileShape
 }
 
 type World struct {
 	Min, Max Coord
 	Tiles    []WorldTile
+	Entities []Entity
+	Triggers []Trigger
+	Meta     LevelMeta
 
 	rendered map[Coord]*image.RGBA
+	scaled   map[Coord]*image.RGBA // rendered, zoomed in or out; thrown away with it
+	zoom     int                   // how far scaled is zoomed
+	loaded   time.Time             // when the client received the world, for the title card
 }
 
 func (w *World) index(x, y int64) (i int, out int64) {
@@ -55,63 +131,209 @@ func (w *World) index(x, y int64) (i int, out int64) {
 }
 
 func (w *World) Render(img draw.Image, offX, offY int64) {
+	w.RenderZoom(img, offX, offY, 0)
+}
+
+// RenderZoom is Render with the world drawn 2 to the power of zoom times as
+// big. offX and offY are in zoomed pixels. Only the pieces of the render
+// cache for the current zoom are kept, and pieces drawn only to be zoomed are
+// not kept at all.
+func (w *World) RenderZoom(img draw.Image, offX, offY int64, zoom int) {
 	if w.rendered == nil {
 		w.rendered = make(map[Coord]*image.RGBA)
+		w.scaled = nil
+	}
+	if w.scaled == nil || w.zoom != zoom {
+		w.scaled = make(map[Coord]*image.RGBA)
+		w.zoom = zoom
 	}
 
-	min, max := Coord{0, 0}, Coord{int64(img.Bounds().Dx()) + TileSize*WorldRenderCacheSize, int64(img.Bounds().Dy()) + TileSize*WorldRenderCacheSize}
-	min = min.Sub(Coord{offX, offY}).Floor(TileSize * WorldRenderCacheSize)
-	max = max.Sub(Coord{offX, offY}).Floor(TileSize * WorldRenderCacheSize)
-	min.X, min.Y = min.X/TileSize/WorldRenderCacheSize, min.Y/TileSize/WorldRenderCacheSize
-	max.X, max.Y = max.X/TileSize/WorldRenderCacheSize, max.Y/TileSize/WorldRenderCacheSize
+	size := zoomed(TileSize*WorldRenderCacheSize, zoom)
+
+	min, max := Coord{0, 0}, Coord{int64(img.Bounds().Dx()) + size, int64(img.Bounds().Dy()) + size}
+	min = min.Sub(Coord{offX, offY}).Floor(size)
+	max = max.Sub(Coord{offX, offY}).Floor(size)
+	min.X, min.Y = min.X/size, min.Y/size
+	max.X, max.Y = max.X/size, max.Y/size
 
 	for cx := min.X; cx < max.X; cx++ {
 		for cy := min.Y; cy < max.Y; cy++ {
-			cache, ok := w.rendered[Coord{cx, cy}]
-			if !ok {
-				cache = image.NewRGBA(image.Rect(0, 0, TileSize*WorldRenderCacheSize, TileSize*WorldRenderCacheSize))
-				w.rendered[Coord{cx, cy}] = cache
-				for x := 0; x < WorldRenderCacheSize; x++ {
-					for y := 0; y < WorldRenderCacheSize; y++ {
-						tx, ty := cx*WorldRenderCacheSize+int64(x), cy*WorldRenderCacheSize+int64(y)
-						i := 0
-						if w.Solid(tx, ty) {
-							i |= 1 << 0
-						}
-						if w.Solid(tx-1, ty) {
-							i |= 1 << 1
-						}
-						if w.Solid(tx-1, ty-1) {
-							i |= 1 << 2
-						}
-						if w.Solid(tx, ty-1) {
-							i |= 1 << 3
-						}
-						if w.Solid(tx+1, ty-1) {
-							i |= 1 << 4
-						}
-						if w.Solid(tx+1, ty) {
-							i |= 1 << 5
-						}
-						if w.Solid(tx+1, ty+1) {
-							i |= 1 << 6
-						}
-						if w.Solid(tx, ty+1) {
-							i |= 1 << 7
-						}
-						if w.Solid(tx-1, ty+1) {
-							i |= 1 << 8
-						}
-						tr := terrain[w.Tile(tx, ty)]
-						tm := tilemask[i]
-						r := image.Rect(x*TileSize, y*TileSize, x*TileSize+TileSize, y*TileSize+TileSize)
-						draw.DrawMask(cache, r, tr, tr.Rect.Min, tm, tm.Rect.Min, draw.Over)
+			c := Coord{cx, cy}
+			var cache *image.RGBA
+			if zoom == 0 {
+				cache = w.rendered[c]
+				if cache == nil {
+					cache = w.renderChunk(c)
+					w.rendered[c] = cache
+				}
+			} else {
+				cache = w.scaled[c]
+				if cache == nil {
+					src := w.rendered[c]
+					if src == nil {
+						src = w.renderChunk(c)
+					}
+					cache = scaleImage(src, zoom)
+					w.scaled[c] = cache
+				}
+			}
+			draw.Draw(img, cache.Rect.Add(img.Bounds().Min).Add(image.Pt(int(offX+cx*size), int(offY+cy*size))), cache, image.ZP, draw.Over)
+		}
+	}
+
+	for _, m := range [...]map[Coord]*image.RGBA{w.rendered, w.scaled} {
+		if len(m) <= WorldRenderCacheChunks {
+			continue
+		}
+		for c := range m {
+			if c.X < min.X || c.Y < min.Y || c.X >= max.X || c.Y >= max.Y {
+				delete(m, c)
+			}
+		}
+	}
+}
+
+// redraw throws away the pieces of the render cache that show the tile at c
+// or its neighbours. Past the edge of the level, tiles repeat forever, so a
+// tile on the edge is shown all the way out.
+func (w *World) redraw(c Coord) {
+	const far = 1<<63 - 1
+	min, max := c.Sub(Coord{1, 1}), c.Add(Coord{1, 1})
+	if c.X <= w.Min.X {
+		min.X = -far
+	}
+	if c.Y <= w.Min.Y {
+		min.Y = -far
+	}
+	if c.X >= w.Max.X {
+		max.X = far
+	}
+	if c.Y >= w.Max.Y {
+		max.Y = far
+	}
+	min = min.Floor(WorldRenderCacheSize)
+	min.X, min.Y = min.X/WorldRenderCacheSize, min.Y/WorldRenderCacheSize
+	max = max.Floor(WorldRenderCacheSize)
+	max.X, max.Y = max.X/WorldRenderCacheSize, max.Y/WorldRenderCacheSize
+
+	for _, m := range [...]map[Coord]*image.RGBA{w.rendered, w.scaled} {
+		for k := range m {
+			if k.X >= min.X && k.Y >= min.Y && k.X <= max.X && k.Y <= max.Y {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+// renderChunk draws the WorldRenderCacheSize square of tiles at c.
+func (w *World) renderChunk(c Coord) *image.RGBA {
+	terrain := w.Terrain()
+	cx, cy := c.X, c.Y
+
+	cache := image.NewRGBA(image.Rect(0, 0, TileSize*WorldRenderCacheSize, TileSize*WorldRenderCacheSize))
+	for x := 0; x < WorldRenderCacheSize; x++ {
+		for y := 0; y < WorldRenderCacheSize; y++ {
+			tx, ty := cx*WorldRenderCacheSize+int64(x), cy*WorldRenderCacheSize+int64(y)
+			i := 0
+			if w.Solid(tx, ty) {
+				i |= 1 << 0
+			}
+			if w.Solid(tx-1, ty) && w.Shape(tx-1, ty).Right() <= w.Shape(tx, ty).Left() {
+				i |= 1 << 1
+			}
+			if w.Solid(tx-1, ty-1) {
+				i |= 1 << 2
+			}
+			if w.Solid(tx, ty-1) {
+				i |= 1 << 3
+			}
+			if w.Solid(tx+1, ty-1) {
+				i |= 1 << 4
+			}
+			if w.Solid(tx+1, ty) && w.Shape(tx+1, ty).Left() <= w.Shape(tx, ty).Right() {
+				i |= 1 << 5
+			}
+			if w.Solid(tx+1, ty+1) {
+				i |= 1 << 6
+			}
+			if w.Solid(tx, ty+1) && w.Shape(tx, ty+1) == TileShape_Full {
+				i |= 1 << 7
+			}
+			if w.Solid(tx-1, ty+1) {
+				i |= 1 << 8
+			}
+			tr := terrain[w.Tile(tx, ty)]
+			tm := tilemask[i]
+			if s := w.Shape(tx, ty); s != TileShape_Full && i&(1<<0) != 0 {
+				tm = shapedTilemask(i, s)
+			}
+			r := image.Rect(x*TileSize, y*TileSize, x*TileSize+TileSize, y*TileSize+TileSize)
+			draw.DrawMask(cache, r, tr, tr.Rect.Min, tm, tm.Rect.Min, draw.Over)
+			switch w.Special(tx, ty) {
+			case SpecialTile_Fragile:
+				if i&(1<<0) != 0 {
+					draw.DrawMask(cache, r, image.Black, image.ZP, fragilemask, image.ZP, draw.Over)
+				}
+			case SpecialTile_Liquid:
+				if i&(1<<0) == 0 {
+					draw.Draw(cache, r, liquidfill, image.ZP, draw.Over)
+				}
+			case SpecialTile_Switch:
+				if i&(1<<0) != 0 {
+					draw.Draw(cache, image.Rect(r.Min.X+TileSize/4, r.Min.Y, r.Max.X-TileSize/4, r.Min.Y+2), switchfill, image.ZP, draw.Src)
+				}
+			}
+		}
+	}
+	return cache
+}
+
+// zoomed returns v, a distance in pixels, made 2 to the power of zoom times as
+// big, rounded down.
+func zoomed(v int64, zoom int) int64 {
+	if zoom < 0 {
+		return v >> uint(-zoom)
+	}
+	return v << uint(zoom)
+}
+
+// scaleImage returns a copy of src that is 2 to the power of zoom times as
+// big. Zooming out averages each square of pixels that becomes one pixel, and
+// zooming in repeats each pixel.
+func scaleImage(src *image.RGBA, zoom int) *image.RGBA {
+	b := src.Rect
+	if zoom >= 0 {
+		f := 1 << uint(zoom)
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx()*f, b.Dy()*f))
+		for y := 0; y < dst.Rect.Dy(); y++ {
+			for x := 0; x < dst.Rect.Dx(); x++ {
+				i, j := src.PixOffset(b.Min.X+x/f, b.Min.Y+y/f), dst.PixOffset(x, y)
+				copy(dst.Pix[j:j+4], src.Pix[i:i+4])
+			}
+		}
+		return dst
+	}
+
+	f := 1 << uint(-zoom)
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()/f, b.Dy()/f))
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			var sum [4]int
+			for sy := 0; sy < f; sy++ {
+				for sx := 0; sx < f; sx++ {
+					i := src.PixOffset(b.Min.X+x*f+sx, b.Min.Y+y*f+sy)
+					for k := range sum {
+						sum[k] += int(src.Pix[i+k])
 					}
 				}
 			}
-			draw.Draw(img, cache.Rect.Add(img.Bounds().Min).Add(image.Pt(int(offX+cx*TileSize*WorldRenderCacheSize), int(offY+cy*TileSize*WorldRenderCacheSize))), cache, image.ZP, draw.Over)
+			j := dst.PixOffset(x, y)
+			for k := range sum {
+				dst.Pix[j+k] = uint8(sum[k] / (f * f))
+			}
 		}
 	}
+	return dst
 }
 
 func (w *World) Outside(x, y int64) int64 {
@@ -134,6 +356,11 @@ func (w *World) Special(x, y int64) SpecialTile {
 	return w.Tiles[i].SpecialTile
 }
 
+func (w *World) Shape(x, y int64) TileShape {
+	i, _ := w.index(x, y)
+	return w.Tiles[i].Shape
+}
+
 func (w *World) ensureTileExists(x, y int64) {
 	newMin, newMax := w.Min, w.Max
 	if w.Min.X > x {
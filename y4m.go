@@ -7,7 +7,6 @@ import (
 	"encoding/gob"
 	"fmt"
 	"github.com/BenLubar/bindiff"
-	"github.com/Rnoadm/wdvn/res"
 	"image"
 	"image/color"
 	"io"
@@ -56,97 +55,115 @@ func EncodeVideo(w io.Writer, r io.Reader) error {
 	graphicsInit()
 
 	frames := make(chan *image.YCbCr)
-	br := bufio.NewReader(r)
 	go func() {
 		defer close(frames)
 
-		var (
-			world World
-			state State
-			buf   bytes.Buffer
-			old   []byte
-			src   = image.NewRGBA(image.Rect(0, 0, *flagWidth, *flagHeight))
-		)
-
-		version, err := binary.ReadUvarint(br)
+		src := image.NewRGBA(image.Rect(0, 0, *flagWidth, *flagHeight))
+		err := ReadReplay(r, func(state *State) {
+			Render(src, 0, state, nil)
+			frames <- toYCbCr(src)
+		})
 		if err != nil {
 			log.Fatal(err)
 		}
-		switch version {
-		case 0:
-			log.Fatal("invalid replay version")
-		case 1:
-			// do nothing
-		default:
-			log.Fatal("replay from newer version")
+	}()
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	return EncodeAll(bw, frames)
+}
+
+// ReadReplay calls frame with the state after each tick of a replay. The
+// state is only valid until frame returns, apart from the last one.
+func ReadReplay(r io.Reader, frame func(*State)) error {
+	var (
+		world World
+		state State
+		buf   bytes.Buffer
+		old   []byte
+		br    = bufio.NewReader(r)
+	)
+
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return err
+	}
+	switch version {
+	case 0:
+		return fmt.Errorf("invalid replay version")
+	case 1, 2:
+		// do nothing
+	default:
+		return fmt.Errorf("replay from newer version")
+	}
+
+	for {
+		buf.Reset()
+
+		l, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
 		}
 
-		for {
-			buf.Reset()
+		n, err := io.CopyN(&buf, br, int64(l))
+		if err == nil && n != int64(l) {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
 
-			l, err := binary.ReadUvarint(br)
-			if err == io.EOF {
-				return
-			}
+		t, err := buf.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch t {
+		case 2:
+			err = ApplyTuning(buf.Bytes())
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
+			continue
 
-			n, err := io.CopyN(&buf, br, int64(l))
-			if err == nil && n != int64(l) {
-				err = io.ErrUnexpectedEOF
-			}
+		case 0:
+			world, state = World{}, State{}
+			err = gob.NewDecoder(&buf).Decode(&world)
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
 
-			t, err := buf.ReadByte()
+			old = make([]byte, buf.Len())
+			copy(old, buf.Bytes())
+
+			err = gob.NewDecoder(&buf).Decode(&state)
 			if err != nil {
-				log.Fatal(err)
+				return err
 			}
 
-			switch t {
-			case 0:
-				world, state = World{}, State{}
-				err = gob.NewDecoder(&buf).Decode(&world)
-				if err != nil {
-					log.Fatal(err)
-				}
-
-				old = make([]byte, buf.Len())
-				copy(old, buf.Bytes())
-
-				err = gob.NewDecoder(&buf).Decode(&state)
-				if err != nil {
-					log.Fatal(err)
-				}
-
-			case 1:
-				if state.world == nil {
-					log.Fatal("diff packet came before world")
-				}
-
-				old, err = bindiff.Forward(old, buf.Bytes())
-				if err != nil {
-					log.Fatal(err)
-				}
-				state = State{}
-				err = gob.NewDecoder(bytes.NewReader(old)).Decode(&state)
-				if err != nil {
-					log.Fatal(err)
-				}
+		case 1:
+			if state.world == nil {
+				return fmt.Errorf("diff packet came before world")
 			}
 
-			state.world = &world
-			Render(src, res.Man_Whip, &state, nil)
-			frames <- toYCbCr(src)
+			old, err = bindiff.Forward(old, buf.Bytes())
+			if err != nil {
+				return err
+			}
+			state = State{}
+			err = gob.NewDecoder(bytes.NewReader(old)).Decode(&state)
+			if err != nil {
+				return err
+			}
 		}
-	}()
 
-	bw := bufio.NewWriter(w)
-	defer bw.Flush()
-
-	return EncodeAll(bw, frames)
+		state.world = &world
+		frame(&state)
+	}
 }
 
 func toYCbCr(src *image.RGBA) *image.YCbCr {